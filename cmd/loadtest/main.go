@@ -1,16 +1,30 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 
+	"github.com/danube-messaging/loadtest_danube/pkg/compare"
 	"github.com/danube-messaging/loadtest_danube/pkg/config"
+	"github.com/danube-messaging/loadtest_danube/pkg/history"
+	"github.com/danube-messaging/loadtest_danube/pkg/report"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
 	"github.com/danube-messaging/loadtest_danube/pkg/runner"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
 	"github.com/spf13/cobra"
 )
 
+// exitThresholdsFailed is the exit code of a run that completed but breached a threshold;
+// other failures exit with 1.
+const exitThresholdsFailed = 3
+
+// exitRegression is the exit code of a comparison that found a regression, kept apart from
+// exitThresholdsFailed so CI can tell the two verdicts apart.
+const exitRegression = 4
+
 func main() {
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	if err := rootCmd.Execute(); err != nil {
@@ -27,6 +41,9 @@ func init() {
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(compareCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(historyCmd)
 }
 
 var runCmd = &cobra.Command{
@@ -44,6 +61,15 @@ var runCmd = &cobra.Command{
 		if err != nil {
 			log.Fatalf("failed to load config: %v", err)
 		}
+		runID, _ := cmd.Flags().GetString("run-id")
+		config.ApplyRunID(cfg, runID)
+		labels, _ := cmd.Flags().GetStringToString("label")
+		for k, v := range labels {
+			if cfg.Labels == nil {
+				cfg.Labels = map[string]string{}
+			}
+			cfg.Labels[k] = v
+		}
 		if errs := config.Validate(cfg); len(errs) > 0 {
 			for _, e := range errs {
 				log.Printf("config error: %v", e)
@@ -54,7 +80,20 @@ var runCmd = &cobra.Command{
 		fmt.Printf("Loaded test '%s' targeting %s\n", cfg.TestName, cfg.Danube.ServiceURL)
 		fmt.Printf("Topics: %d, Producers: %d groups, Consumers: %d groups\n",
 			len(cfg.Topics), len(cfg.Producers), len(cfg.Consumers))
-		if err := runner.Run(cfg); err != nil {
+		if cfg.Execution.RunID != "" {
+			fmt.Printf("Run ID: %s\n", cfg.Execution.RunID)
+		}
+		reportFormat, _ := cmd.Flags().GetString("report")
+		if _, err := report.NewRenderer(reportFormat); err != nil {
+			log.Fatal(err)
+		}
+		junit, _ := cmd.Flags().GetString("junit")
+		tuiMode, _ := cmd.Flags().GetBool("tui")
+		if err := runner.Run(cfg, runner.Options{Report: reportFormat, JUnit: junit, TUI: tuiMode}); err != nil {
+			if errors.Is(err, thresholds.ErrFailed) {
+				log.Printf("run failed: %v", err)
+				os.Exit(exitThresholdsFailed)
+			}
 			log.Fatalf("run failed: %v", err)
 		}
 	},
@@ -63,6 +102,11 @@ var runCmd = &cobra.Command{
 func init() {
 	runCmd.Flags().String("config", "", "Path to YAML config file")
 	runCmd.Flags().String("duration", "", "Override test duration (e.g. 2m)")
+	runCmd.Flags().String("run-id", "", "Run ID substituted for {run_id} in names and stamped on messages (\"auto\" generates one)")
+	runCmd.Flags().String("report", "text", "Final report format: text|markdown|html")
+	runCmd.Flags().String("junit", "", "Also write a JUnit XML report to this file")
+	runCmd.Flags().Bool("tui", false, "Show a full-screen live dashboard instead of periodic stats lines")
+	runCmd.Flags().StringToString("label", nil, "Label the run in the results history, e.g. --label broker=0.5,env=ci (overrides config labels)")
 }
 
 var validateCmd = &cobra.Command{
@@ -77,6 +121,7 @@ var validateCmd = &cobra.Command{
 		if err != nil {
 			log.Fatalf("failed to load config: %v", err)
 		}
+		config.ApplyRunID(cfg, "")
 		if errs := config.Validate(cfg); len(errs) > 0 {
 			fmt.Println("Config validation failed:")
 			for _, e := range errs {
@@ -129,3 +174,119 @@ func init() {
 	initCmd.Flags().String("template", "simple", "Template to generate: simple|stress")
 	initCmd.Flags().String("output", "", "Output file path (optional)")
 }
+
+var compareCmd = &cobra.Command{
+	Use:   "compare baseline.json candidate.json",
+	Short: "Compare two exported results and flag regressions",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		baseline, err := results.Load(args[0])
+		if err != nil {
+			log.Fatalf("failed to load baseline: %v", err)
+		}
+		candidate, err := results.Load(args[1])
+		if err != nil {
+			log.Fatalf("failed to load candidate: %v", err)
+		}
+		tol := compare.DefaultTolerances
+		tol.ThroughputPercent, _ = cmd.Flags().GetFloat64("tolerance-throughput")
+		tol.LatencyPercent, _ = cmd.Flags().GetFloat64("tolerance-latency")
+		tol.Errors, _ = cmd.Flags().GetFloat64("tolerance-errors")
+		tol.Loss, _ = cmd.Flags().GetFloat64("tolerance-loss")
+		tol.Duplicates, _ = cmd.Flags().GetFloat64("tolerance-duplicates")
+		format, _ := cmd.Flags().GetString("format")
+
+		rep := compare.Compare(baseline, candidate, tol)
+		if err := rep.Write(os.Stdout, format); err != nil {
+			log.Fatalf("failed to write comparison: %v", err)
+		}
+		if rep.Regressions > 0 {
+			os.Exit(exitRegression)
+		}
+	},
+}
+
+func init() {
+	d := compare.DefaultTolerances
+	compareCmd.Flags().String("format", "text", "Output format: text|markdown|json")
+	compareCmd.Flags().Float64("tolerance-throughput", d.ThroughputPercent, "Allowed throughput drop in percent")
+	compareCmd.Flags().Float64("tolerance-latency", d.LatencyPercent, "Allowed latency increase in percent (each percentile)")
+	compareCmd.Flags().Float64("tolerance-errors", d.Errors, "Allowed absolute increase in errors")
+	compareCmd.Flags().Float64("tolerance-loss", d.Loss, "Allowed absolute increase in lost messages (overall and per key)")
+	compareCmd.Flags().Float64("tolerance-duplicates", d.Duplicates, "Allowed absolute increase in duplicates (overall and per key)")
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report result.json",
+	Short: "Render the final report of a saved result",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		res, err := results.Load(args[0])
+		if err != nil {
+			log.Fatalf("failed to load result: %v", err)
+		}
+		format, _ := cmd.Flags().GetString("format")
+		r, err := report.NewRenderer(format)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out := os.Stdout
+		if path, _ := cmd.Flags().GetString("output"); path != "" {
+			if out, err = os.Create(path); err != nil {
+				log.Fatalf("failed to create output: %v", err)
+			}
+			defer out.Close()
+		}
+		if err := r.Render(out, res); err != nil {
+			log.Fatalf("failed to render report: %v", err)
+		}
+	},
+}
+
+func init() {
+	reportCmd.Flags().String("format", "text", "Report format: text|markdown|html|junit")
+	reportCmd.Flags().String("output", "", "Write the report to this file instead of stdout")
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List past runs of a test with throughput and latency trends",
+	Run: func(cmd *cobra.Command, args []string) {
+		dir, _ := cmd.Flags().GetString("dir")
+		q := history.Query{}
+		q.TestName, _ = cmd.Flags().GetString("test")
+		q.ConfigHash, _ = cmd.Flags().GetString("config-hash")
+		q.Labels, _ = cmd.Flags().GetStringToString("label")
+		limit, _ := cmd.Flags().GetInt("limit")
+		if q.TestName == "" {
+			log.Fatal("--test is required")
+		}
+
+		entries, skipped, err := history.Load(dir)
+		if err != nil {
+			log.Fatalf("failed to load history: %v", err)
+		}
+		if skipped > 0 {
+			log.Printf("skipped %d unreadable history lines", skipped)
+		}
+		entries = history.Filter(entries, q)
+		if len(entries) == 0 {
+			fmt.Printf("No runs of %q in %s\n", q.TestName, filepath.Join(dir, history.IndexFile))
+			return
+		}
+		if limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+		if err := history.Write(os.Stdout, entries); err != nil {
+			log.Fatalf("failed to write history: %v", err)
+		}
+	},
+}
+
+func init() {
+	historyCmd.Flags().String("test", "", "Test name to list")
+	historyCmd.Flags().String("dir", "./results", "Export directory holding the history index (metrics.export_path)")
+	historyCmd.Flags().String("config-hash", "", "Only runs of this config hash")
+	historyCmd.Flags().StringToString("label", nil, "Only runs with these labels, e.g. --label env=ci")
+	historyCmd.Flags().Int("limit", 20, "Show at most the last N runs (0 for all)")
+}
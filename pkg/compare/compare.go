@@ -0,0 +1,162 @@
+// Package compare diffs two exported results and flags regressions beyond configured tolerances.
+package compare
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+)
+
+// Tolerances bound how much worse the candidate may be before a change counts as a regression.
+type Tolerances struct {
+	ThroughputPercent float64 // allowed throughput drop, in percent of the baseline
+	LatencyPercent    float64 // allowed latency increase, in percent of the baseline
+	Errors            float64 // allowed absolute increase in errors
+	Loss              float64 // allowed absolute increase in loss, overall and per key
+	Duplicates        float64 // allowed absolute increase in duplicates, overall and per key
+}
+
+// DefaultTolerances allow a 5% throughput drop, 10% latency increase and no new errors, loss or duplicates.
+var DefaultTolerances = Tolerances{ThroughputPercent: 5, LatencyPercent: 10}
+
+// Delta compares one headline metric.
+type Delta struct {
+	Metric        string  `json:"metric"`
+	Baseline      float64 `json:"baseline"`
+	Candidate     float64 `json:"candidate"`
+	Change        float64 `json:"change"`
+	ChangePercent float64 `json:"change_percent"` // 0 when the baseline is 0
+	Regression    bool    `json:"regression"`
+}
+
+// KeyDiff compares the integrity of one topic+subscription+producer key.
+type KeyDiff struct {
+	Key                 string `json:"key"`
+	Status              string `json:"status"` // both|added|removed
+	BaselineLoss        uint64 `json:"baseline_loss"`
+	CandidateLoss       uint64 `json:"candidate_loss"`
+	BaselineDuplicates  uint64 `json:"baseline_duplicates"`
+	CandidateDuplicates uint64 `json:"candidate_duplicates"`
+	Regression          bool   `json:"regression"`
+}
+
+// Report is the comparison of a candidate run against a baseline.
+type Report struct {
+	Baseline    string     `json:"baseline"`
+	Candidate   string     `json:"candidate"`
+	Tolerances  Tolerances `json:"tolerances"`
+	Deltas      []Delta    `json:"deltas"`
+	Keys        []KeyDiff  `json:"keys,omitempty"`
+	Regressions int        `json:"regressions"`
+}
+
+// Compare diffs candidate against baseline.
+func Compare(baseline, candidate results.Result, tol Tolerances) Report {
+	b, c := baseline.Snapshot, candidate.Snapshot
+	rep := Report{Baseline: label(baseline), Candidate: label(candidate), Tolerances: tol}
+	higherBetter := func(name string, bv, cv float64) {
+		d := delta(name, bv, cv)
+		d.Regression = bv > 0 && cv < bv*(1-tol.ThroughputPercent/100)
+		rep.add(d)
+	}
+	// a baseline without latency samples cannot vouch for any candidate latency, and a 0 ms
+	// baseline allows no increase at all
+	noBaseLatency := b.LatencySamples == 0 && c.LatencySamples > 0
+	lowerBetterPct := func(name string, bv, cv float64) {
+		d := delta(name, bv, cv)
+		d.Regression = noBaseLatency || cv > bv*(1+tol.LatencyPercent/100)
+		rep.add(d)
+	}
+	lowerBetterAbs := func(name string, bv, cv, allowed float64) {
+		d := delta(name, bv, cv)
+		d.Regression = cv > bv+allowed
+		rep.add(d)
+	}
+	higherBetter("throughput_sent", b.ThroughputSent, c.ThroughputSent)
+	higherBetter("throughput_recv", b.ThroughputRecv, c.ThroughputRecv)
+	lowerBetterPct("latency_p50_ms", b.LatencyP50Ms, c.LatencyP50Ms)
+	lowerBetterPct("latency_p95_ms", b.LatencyP95Ms, c.LatencyP95Ms)
+	lowerBetterPct("latency_p99_ms", b.LatencyP99Ms, c.LatencyP99Ms)
+	lowerBetterPct("latency_max_ms", b.LatencyMaxMs, c.LatencyMaxMs)
+	lowerBetterAbs("errors", float64(b.Errors), float64(c.Errors), tol.Errors)
+	lowerBetterAbs("estimated_loss", float64(b.EstimatedLoss), float64(c.EstimatedLoss), tol.Loss)
+	lowerBetterAbs("duplicates", float64(b.Duplicates), float64(c.Duplicates), tol.Duplicates)
+
+	rep.Keys = diffKeys(baseline, candidate, tol)
+	for _, k := range rep.Keys {
+		if k.Regression {
+			rep.Regressions++
+		}
+	}
+	return rep
+}
+
+func (r *Report) add(d Delta) {
+	r.Deltas = append(r.Deltas, d)
+	if d.Regression {
+		r.Regressions++
+	}
+}
+
+func delta(name string, b, c float64) Delta {
+	d := Delta{Metric: name, Baseline: b, Candidate: c, Change: c - b}
+	if b != 0 {
+		d.ChangePercent = (c - b) * 100 / b
+	}
+	return d
+}
+
+func label(r results.Result) string {
+	if r.RunID != "" {
+		return r.TestName + " (" + r.RunID + ")"
+	}
+	return r.TestName
+}
+
+// diffKeys matches integrity entries by topic, subscription and producer. Run IDs are
+// replaced by their placeholder so that per-run names still line up. A key missing from the
+// candidate is a regression: nothing of it arrived, so its integrity is no longer verified.
+func diffKeys(baseline, candidate results.Result, tol Tolerances) []KeyDiff {
+	index := func(r results.Result) map[string]metrics.IntegrityEntry {
+		m := make(map[string]metrics.IntegrityEntry, len(r.Snapshot.IntegrityBreakdown))
+		for _, e := range r.Snapshot.IntegrityBreakdown {
+			k := e.Topic + " | " + e.Subscription + " | " + e.Producer
+			if r.RunID != "" {
+				k = strings.ReplaceAll(k, r.RunID, "{run_id}")
+			}
+			m[k] = e
+		}
+		return m
+	}
+	bi, ci := index(baseline), index(candidate)
+	var out []KeyDiff
+	for k, be := range bi {
+		d := KeyDiff{Key: k, Status: "removed", BaselineLoss: be.Loss, BaselineDuplicates: be.Duplicates, Regression: true}
+		if ce, ok := ci[k]; ok {
+			d.Status = "both"
+			d.CandidateLoss, d.CandidateDuplicates = ce.Loss, ce.Duplicates
+			d.Regression = float64(ce.Loss) > float64(be.Loss)+tol.Loss ||
+				float64(ce.Duplicates) > float64(be.Duplicates)+tol.Duplicates
+		}
+		out = append(out, d)
+	}
+	for k, ce := range ci {
+		if _, ok := bi[k]; ok {
+			continue
+		}
+		out = append(out, KeyDiff{
+			Key: k, Status: "added", CandidateLoss: ce.Loss, CandidateDuplicates: ce.Duplicates,
+			Regression: float64(ce.Loss) > tol.Loss || float64(ce.Duplicates) > tol.Duplicates,
+		})
+	}
+	// regressions first, then by key
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Regression != out[j].Regression {
+			return out[i].Regression
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
@@ -0,0 +1,128 @@
+package compare
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+)
+
+func result(runID string, tx, p99 float64, errs uint64, entries ...metrics.IntegrityEntry) results.Result {
+	samples := 0
+	if p99 > 0 {
+		samples = 100
+	}
+	return results.Result{
+		TestName: "soak",
+		RunID:    runID,
+		Snapshot: metrics.Snapshot{
+			ThroughputSent:     tx,
+			ThroughputRecv:     tx,
+			LatencyP99Ms:       p99,
+			LatencySamples:     samples,
+			Errors:             errs,
+			IntegrityBreakdown: entries,
+		},
+	}
+}
+
+func findDelta(t *testing.T, r Report, metric string) Delta {
+	t.Helper()
+	for _, d := range r.Deltas {
+		if d.Metric == metric {
+			return d
+		}
+	}
+	t.Fatalf("no delta for %s", metric)
+	return Delta{}
+}
+
+func TestCompareWithinTolerance(t *testing.T) {
+	base := result("", 1000, 10, 0)
+	cand := result("", 960, 10.5, 0)
+	rep := Compare(base, cand, DefaultTolerances)
+	if rep.Regressions != 0 {
+		t.Fatalf("expected no regressions, got %+v", rep.Deltas)
+	}
+	if d := findDelta(t, rep, "throughput_sent"); d.Change != -40 || d.ChangePercent != -4 {
+		t.Fatalf("unexpected throughput delta %+v", d)
+	}
+}
+
+func TestCompareRegressions(t *testing.T) {
+	base := result("", 1000, 10, 0)
+	cand := result("", 900, 12, 3)
+	rep := Compare(base, cand, DefaultTolerances)
+	for _, m := range []string{"throughput_sent", "latency_p99_ms", "errors"} {
+		if !findDelta(t, rep, m).Regression {
+			t.Errorf("%s should regress", m)
+		}
+	}
+	loose := Tolerances{ThroughputPercent: 20, LatencyPercent: 50, Errors: 5}
+	if rep := Compare(base, cand, loose); rep.Regressions != 0 {
+		t.Fatalf("loose tolerances should accept the candidate, got %d regressions", rep.Regressions)
+	}
+}
+
+func TestCompareKeysAcrossRunIDs(t *testing.T) {
+	base := result("r1", 1000, 10, 0,
+		metrics.IntegrityEntry{Topic: "/default/t_r1", Subscription: "s", Producer: "p-0"},
+		metrics.IntegrityEntry{Topic: "/default/gone_r1", Subscription: "s", Producer: "p-0"})
+	cand := result("r2", 1000, 10, 0,
+		metrics.IntegrityEntry{Topic: "/default/t_r2", Subscription: "s", Producer: "p-0", Loss: 4})
+	rep := Compare(base, cand, DefaultTolerances)
+	if len(rep.Keys) != 2 {
+		t.Fatalf("keys got %+v", rep.Keys)
+	}
+	// both regress, so they are ordered by key
+	k := rep.Keys[1]
+	if k.Key != "/default/t_{run_id} | s | p-0" || k.Status != "both" || k.CandidateLoss != 4 || !k.Regression {
+		t.Fatalf("unexpected key diff %+v", k)
+	}
+	if k := rep.Keys[0]; k.Status != "removed" || !k.Regression {
+		t.Fatalf("removed key should regress: %+v", k)
+	}
+	if rep.Regressions != 2 {
+		t.Fatalf("regressions got %d want 2", rep.Regressions)
+	}
+}
+
+func TestCompareLatencyWithoutBaselineSamples(t *testing.T) {
+	rep := Compare(result("", 1000, 0, 0), result("", 1000, 10, 0), DefaultTolerances)
+	if d := findDelta(t, rep, "latency_p99_ms"); !d.Regression {
+		t.Fatalf("a baseline without latency samples must not pass the candidate: %+v", d)
+	}
+	if rep := Compare(result("", 1000, 0, 0), result("", 1000, 0, 0), DefaultTolerances); rep.Regressions != 0 {
+		t.Fatalf("no samples on either side is no regression, got %+v", rep.Deltas)
+	}
+}
+
+func TestWriteFormats(t *testing.T) {
+	rep := Compare(result("", 1000, 10, 0), result("", 900, 10, 0), DefaultTolerances)
+	var text, md, js bytes.Buffer
+	if err := rep.Write(&text, "text"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "throughput_sent") || !strings.Contains(text.String(), "Result: REGRESSION") {
+		t.Fatalf("unexpected text output:\n%s", text.String())
+	}
+	if err := rep.Write(&md, "markdown"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(md.String(), "| throughput_sent | 1000.00 | 900.00 |") {
+		t.Fatalf("unexpected markdown output:\n%s", md.String())
+	}
+	if err := rep.Write(&js, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var back Report
+	if err := json.Unmarshal(js.Bytes(), &back); err != nil || back.Regressions != rep.Regressions {
+		t.Fatalf("json round trip: %v %+v", err, back)
+	}
+	if err := rep.Write(&js, "yaml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
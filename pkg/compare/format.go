@@ -0,0 +1,80 @@
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Write renders the report as text, markdown or json.
+func (r Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "", "text":
+		return r.writeText(w)
+	case "markdown", "md":
+		return r.writeMarkdown(w)
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return fmt.Errorf("unknown format %q (want text|markdown|json)", format)
+}
+
+func (r Report) verdict() string {
+	if r.Regressions > 0 {
+		return fmt.Sprintf("REGRESSION (%d)", r.Regressions)
+	}
+	return "OK"
+}
+
+func mark(regression bool) string {
+	if regression {
+		return "REGRESSION"
+	}
+	return ""
+}
+
+func (r Report) writeText(w io.Writer) error {
+	fmt.Fprintf(w, "Baseline:  %s\nCandidate: %s\n\n", r.Baseline, r.Candidate)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tBASELINE\tCANDIDATE\tCHANGE\tCHANGE %\t")
+	for _, d := range r.Deltas {
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%+.2f\t%+.1f%%\t%s\n", d.Metric, d.Baseline, d.Candidate, d.Change, d.ChangePercent, mark(d.Regression))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(r.Keys) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tSTATUS\tLOSS\tDUPLICATES\t")
+		for _, k := range r.Keys {
+			fmt.Fprintf(tw, "%s\t%s\t%d -> %d\t%d -> %d\t%s\n", k.Key, k.Status, k.BaselineLoss, k.CandidateLoss, k.BaselineDuplicates, k.CandidateDuplicates, mark(k.Regression))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "\nResult: %s\n", r.verdict())
+	return err
+}
+
+func (r Report) writeMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "### Load test comparison: %s\n\n", r.verdict())
+	fmt.Fprintf(w, "Baseline: `%s` | Candidate: `%s`\n\n", r.Baseline, r.Candidate)
+	fmt.Fprintln(w, "| Metric | Baseline | Candidate | Change | Change % | |")
+	fmt.Fprintln(w, "|---|---:|---:|---:|---:|---|")
+	for _, d := range r.Deltas {
+		fmt.Fprintf(w, "| %s | %.2f | %.2f | %+.2f | %+.1f%% | %s |\n", d.Metric, d.Baseline, d.Candidate, d.Change, d.ChangePercent, mark(d.Regression))
+	}
+	if len(r.Keys) > 0 {
+		fmt.Fprintln(w, "\n| Key | Status | Loss | Duplicates | |")
+		fmt.Fprintln(w, "|---|---|---:|---:|---|")
+		for _, k := range r.Keys {
+			fmt.Fprintf(w, "| `%s` | %s | %d → %d | %d → %d | %s |\n", k.Key, k.Status, k.BaselineLoss, k.CandidateLoss, k.BaselineDuplicates, k.CandidateDuplicates, mark(k.Regression))
+		}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ package config
 type Config struct {
 	TestName    string `yaml:"test_name"`
 	Description string `yaml:"description,omitempty"`
+	// Labels describe the environment of a run (broker version, cluster, ...) and are kept in the results history
+	Labels map[string]string `yaml:"labels,omitempty"`
 
 	Danube    DanubeConfig    `yaml:"danube"`
 	Execution ExecutionConfig `yaml:"execution"`
@@ -13,18 +15,38 @@ type Config struct {
 	Producers []ProducerGroup `yaml:"producers"`
 	Consumers []ConsumerGroup `yaml:"consumers"`
 
-	Metrics MetricsConfig `yaml:"metrics"`
+	Metrics    MetricsConfig `yaml:"metrics"`
+	Thresholds []Threshold   `yaml:"thresholds,omitempty"`
 }
 
 type DanubeConfig struct {
 	ServiceURL        string `yaml:"service_url"`
 	ConnectionTimeout string `yaml:"connection_timeout,omitempty"`
+
+	Reconnect *ReconnectConfig `yaml:"reconnect,omitempty"`
+}
+
+// ReconnectConfig makes workers recreate producers and resubscribe consumers after a
+// failed send or a closed receive stream, waiting an exponential backoff between attempts.
+type ReconnectConfig struct {
+	InitialBackoff string  `yaml:"initial_backoff,omitempty"` // default 100ms
+	MaxBackoff     string  `yaml:"max_backoff,omitempty"`     // default 10s
+	Multiplier     float64 `yaml:"multiplier,omitempty"`      // default 2
 }
 
 type ExecutionConfig struct {
 	Duration         string `yaml:"duration"`
 	WarmupDuration   string `yaml:"warmup_duration,omitempty"`
 	CooldownDuration string `yaml:"cooldown_duration,omitempty"`
+	// Mode is traffic (default) or idle: producers are created and consumers subscribe,
+	// but nothing is sent, to measure the broker's per-connection overhead.
+	Mode string `yaml:"mode,omitempty"`
+	// RunID is substituted for {run_id} in topic and subscription names and stamped on every
+	// message; "auto" generates one per run. Messages from other runs are counted as stale.
+	RunID string `yaml:"run_id,omitempty"`
+	// ReadyTimeout bounds how long traffic is held until every producer is created
+	// and every consumer is subscribed (default 30s).
+	ReadyTimeout string `yaml:"ready_timeout,omitempty"`
 }
 
 type Topic struct {
@@ -33,6 +55,7 @@ type Topic struct {
 	SchemaType       string `yaml:"schema_type"` // json|string|int64|number
 	JSONSchema       string `yaml:"json_schema,omitempty"`
 	DispatchStrategy string `yaml:"dispatch_strategy,omitempty"` // reliable|non_reliable (default non_reliable)
+	Replicas         int    `yaml:"replicas,omitempty"`          // expand a name containing {i} into this many topics
 }
 
 type ProducerGroup struct {
@@ -42,6 +65,27 @@ type ProducerGroup struct {
 	RatePerSecond int    `yaml:"rate_per_second"`
 	MessageSize   int    `yaml:"message_size"`
 	BatchSize     int    `yaml:"batch_size,omitempty"`
+
+	RoutingKey *RoutingKeyConfig    `yaml:"routing_key,omitempty"`
+	Churn      *ProducerChurnConfig `yaml:"churn,omitempty"`
+}
+
+// ProducerChurnConfig recreates each producer periodically to load the broker's registration path.
+// Either or both limits may be set; a producer is recreated when the first one is reached.
+type ProducerChurnConfig struct {
+	RecreateEveryMessages int    `yaml:"recreate_every_messages,omitempty"`
+	RecreateEvery         string `yaml:"recreate_every,omitempty"` // duration
+}
+
+// RoutingKeyConfig attaches a per-message routing key drawn from a distribution.
+// The client has no routing key field, so the key is only sent as the "key" attribute:
+// it shapes the key spread reported per topic but does not choose the partition.
+type RoutingKeyConfig struct {
+	Distribution string  `yaml:"distribution"`          // uniform|zipf|sequential|hot
+	Keys         int     `yaml:"keys,omitempty"`        // size of the key space (key-0..key-N-1)
+	ZipfSkew     float64 `yaml:"zipf_skew,omitempty"`   // zipf only, must be > 1 (higher is more skewed)
+	HotKey       string  `yaml:"hot_key,omitempty"`     // hot only, defaults to key-0
+	HotPercent   float64 `yaml:"hot_percent,omitempty"` // hot only, share sent to the hot key (default 100)
 }
 
 type ConsumerGroup struct {
@@ -51,6 +95,31 @@ type ConsumerGroup struct {
 	SubscriptionType string `yaml:"subscription_type"` // shared|exclusive|failover
 	Count            int    `yaml:"count"`
 	AckTimeout       string `yaml:"ack_timeout,omitempty"`
+
+	AckMode        string  `yaml:"ack_mode,omitempty"`         // immediate|delayed|every_n|never|drop (default immediate)
+	AckDelay       string  `yaml:"ack_delay,omitempty"`        // delayed: wait before acking
+	AckEvery       int     `yaml:"ack_every,omitempty"`        // every_n: ack only every Nth message
+	AckDropPercent float64 `yaml:"ack_drop_percent,omitempty"` // drop: percentage of messages left unacked
+
+	ProcessingTime *ProcessingTimeConfig `yaml:"processing_time,omitempty"`
+	Churn          *ChurnConfig          `yaml:"churn,omitempty"`
+}
+
+// ChurnConfig periodically closes consumer workers to exercise failover.
+type ChurnConfig struct {
+	Mode         string `yaml:"mode"`                    // restart|kill_active
+	Interval     string `yaml:"interval"`                // time between churn events
+	RestartDelay string `yaml:"restart_delay,omitempty"` // downtime before the closed worker resubscribes (default 1s)
+}
+
+// ProcessingTimeConfig simulates per-message work applied before the ack.
+type ProcessingTimeConfig struct {
+	Mode        string `yaml:"mode"`                  // fixed|uniform|normal|exponential|cpu
+	Duration    string `yaml:"duration,omitempty"`    // fixed and cpu: per message; normal and exponential: mean
+	Min         string `yaml:"min,omitempty"`         // uniform lower bound
+	Max         string `yaml:"max,omitempty"`         // uniform upper bound
+	Stddev      string `yaml:"stddev,omitempty"`      // normal standard deviation
+	Concurrency int    `yaml:"concurrency,omitempty"` // messages processed in parallel per worker (default 1)
 }
 
 type MetricsConfig struct {
@@ -59,5 +128,31 @@ type MetricsConfig struct {
 	Percentiles    []float64 `yaml:"percentiles"`
 	OutputFormat   string    `yaml:"output_format"` // terminal|json|prometheus
 	ExportPath     string    `yaml:"export_path"`
+	HTMLReport     bool      `yaml:"html_report,omitempty"` // also write a self-contained HTML report to export_path
+	CSV            bool      `yaml:"csv,omitempty"`         // also write interval, group and integrity CSV files to export_path
 	Collect        []string  `yaml:"collect"`
 }
+
+// Threshold is a pass/fail assertion evaluated against the final results, e.g.
+// latency_p99_ms < 20 for one consumer group or loss == 0 on reliable topics.
+type Threshold struct {
+	Name   string  `yaml:"name,omitempty"`
+	Metric string  `yaml:"metric"` // see ThresholdMetrics
+	Op     string  `yaml:"op"`     // < <= > >= == !=
+	Value  float64 `yaml:"value"`
+	// Group narrows latency metrics to a consumer group and throughput metrics to a producer group
+	Group string `yaml:"group,omitempty"`
+	// Role narrows error metrics to producer or consumer errors (producer|consumer)
+	Role string `yaml:"role,omitempty"`
+	// Topic and ReliableOnly narrow integrity metrics (loss, duplicates, ordering_violations)
+	Topic        string `yaml:"topic,omitempty"`
+	ReliableOnly bool   `yaml:"reliable_only,omitempty"`
+}
+
+// ThresholdMetrics lists the metrics a threshold can assert on.
+var ThresholdMetrics = map[string]bool{
+	"latency_p50_ms": true, "latency_p95_ms": true, "latency_p99_ms": true, "latency_max_ms": true,
+	"throughput_sent": true, "throughput_recv": true, "throughput_percent_of_target": true,
+	"errors": true, "error_rate_percent": true,
+	"loss": true, "duplicates": true, "unexpected_duplicates": true, "ordering_violations": true,
+}
@@ -0,0 +1,90 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReplicaPlaceholder is substituted with the replica index in templated names.
+const ReplicaPlaceholder = "{i}"
+
+// Expand replaces templated topics (a name containing {i} plus replicas: N) with N concrete
+// topics, and binds producer and consumer groups that reference a template to every instance.
+// Expanded groups substitute {i} in their name and subscription; a group name without {i}
+// gets a "-<i>" suffix so that each instance is reported separately.
+func Expand(cfg *Config) error {
+	templates := map[string]int{}
+	var topics []Topic
+	for i, t := range cfg.Topics {
+		templated := strings.Contains(t.Name, ReplicaPlaceholder)
+		switch {
+		case t.Replicas < 0:
+			return fmt.Errorf("topics[%d].replicas must be >= 0", i)
+		case t.Replicas > 0 && !templated:
+			return fmt.Errorf("topics[%d].name must contain %s when replicas is set", i, ReplicaPlaceholder)
+		case templated && t.Replicas == 0:
+			return fmt.Errorf("topics[%d].replicas is required when the name contains %s", i, ReplicaPlaceholder)
+		case !templated:
+			topics = append(topics, t)
+			continue
+		}
+		templates[t.Name] = t.Replicas
+		for r := 0; r < t.Replicas; r++ {
+			inst := t
+			inst.Name = substitute(t.Name, r)
+			inst.Replicas = 0
+			topics = append(topics, inst)
+		}
+	}
+	cfg.Topics = topics
+
+	var producers []ProducerGroup
+	for _, pg := range cfg.Producers {
+		n, ok := templates[pg.Topic]
+		if !ok {
+			producers = append(producers, pg)
+			continue
+		}
+		for r := 0; r < n; r++ {
+			inst := pg
+			inst.Topic = substitute(pg.Topic, r)
+			inst.Name = instanceName(pg.Name, r)
+			producers = append(producers, inst)
+		}
+	}
+	cfg.Producers = producers
+
+	var consumers []ConsumerGroup
+	for _, cg := range cfg.Consumers {
+		n, ok := templates[cg.Topic]
+		if !ok {
+			consumers = append(consumers, cg)
+			continue
+		}
+		for r := 0; r < n; r++ {
+			inst := cg
+			inst.Topic = substitute(cg.Topic, r)
+			inst.Name = instanceName(cg.Name, r)
+			inst.Subscription = substitute(cg.Subscription, r)
+			consumers = append(consumers, inst)
+		}
+	}
+	cfg.Consumers = consumers
+	return nil
+}
+
+func substitute(s string, i int) string {
+	return strings.ReplaceAll(s, ReplicaPlaceholder, strconv.Itoa(i))
+}
+
+// instanceName names the i-th instance of a group bound to a templated topic.
+func instanceName(name string, i int) string {
+	if strings.Contains(name, ReplicaPlaceholder) {
+		return substitute(name, i)
+	}
+	if name == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s-%d", name, i)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestExpandReplicas(t *testing.T) {
+	cfg := &Config{
+		Topics: []Topic{
+			{Name: "/default/t-{i}", Replicas: 3, Partitions: 2, SchemaType: "string"},
+			{Name: "/default/plain", SchemaType: "string"},
+		},
+		Producers: []ProducerGroup{
+			{Name: "pubs", Topic: "/default/t-{i}", Count: 1},
+			{Name: "one", Topic: "/default/t-1", Count: 1},
+		},
+		Consumers: []ConsumerGroup{
+			{Name: "subs-{i}", Topic: "/default/t-{i}", Subscription: "sub-{i}", Count: 2},
+			{Name: "plain", Topic: "/default/plain", Subscription: "s", Count: 1},
+		},
+	}
+	if err := Expand(cfg); err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	if len(cfg.Topics) != 4 {
+		t.Fatalf("topics got %d want 4", len(cfg.Topics))
+	}
+	for i, want := range []string{"/default/t-0", "/default/t-1", "/default/t-2", "/default/plain"} {
+		if cfg.Topics[i].Name != want {
+			t.Errorf("topics[%d] got %q want %q", i, cfg.Topics[i].Name, want)
+		}
+	}
+	if cfg.Topics[2].Partitions != 2 || cfg.Topics[2].Replicas != 0 {
+		t.Errorf("instance should keep settings and drop replicas: %+v", cfg.Topics[2])
+	}
+
+	if len(cfg.Producers) != 4 {
+		t.Fatalf("producers got %d want 4", len(cfg.Producers))
+	}
+	if p := cfg.Producers[1]; p.Name != "pubs-1" || p.Topic != "/default/t-1" {
+		t.Errorf("unexpected producer instance: %+v", p)
+	}
+	// a group bound to one concrete instance is left alone
+	if p := cfg.Producers[3]; p.Name != "one" || p.Topic != "/default/t-1" {
+		t.Errorf("unexpected concrete producer: %+v", p)
+	}
+
+	if len(cfg.Consumers) != 4 {
+		t.Fatalf("consumers got %d want 4", len(cfg.Consumers))
+	}
+	if c := cfg.Consumers[2]; c.Name != "subs-2" || c.Subscription != "sub-2" || c.Topic != "/default/t-2" || c.Count != 2 {
+		t.Errorf("unexpected consumer instance: %+v", c)
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	cases := map[string]Topic{
+		"replicas without placeholder": {Name: "/default/t", Replicas: 2},
+		"placeholder without replicas": {Name: "/default/t-{i}"},
+		"negative replicas":            {Name: "/default/t-{i}", Replicas: -1},
+	}
+	for name, topic := range cases {
+		if err := Expand(&Config{Topics: []Topic{topic}}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package config
+
+// FindTopic returns the topic definition with the given name, or nil if none is configured.
+func FindTopic(cfg *Config, name string) *Topic {
+	for i := range cfg.Topics {
+		if cfg.Topics[i].Name == name {
+			return &cfg.Topics[i]
+		}
+	}
+	return nil
+}
+
+// ImpliesOrdering reports whether a consumer group should see each producer's messages in order:
+// a single active consumer (exclusive or failover) on a topic with at most one partition.
+func ImpliesOrdering(cfg *Config, cg ConsumerGroup) bool {
+	switch cg.SubscriptionType {
+	case "exclusive", "failover":
+	default:
+		return false
+	}
+	t := FindTopic(cfg, cg.Topic)
+	return t == nil || t.Partitions <= 1
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestImpliesOrdering(t *testing.T) {
+	cfg := &Config{Topics: []Topic{
+		{Name: "/default/single", Partitions: 0},
+		{Name: "/default/one", Partitions: 1},
+		{Name: "/default/many", Partitions: 3},
+	}}
+	cases := []struct {
+		topic, subType string
+		want           bool
+	}{
+		{"/default/single", "exclusive", true},
+		{"/default/single", "failover", true},
+		{"/default/single", "shared", false},
+		{"/default/one", "exclusive", true},
+		{"/default/many", "exclusive", false},
+		{"/default/many", "shared", false},
+	}
+	for _, tc := range cases {
+		cg := ConsumerGroup{Topic: tc.topic, SubscriptionType: tc.subType}
+		if got := ImpliesOrdering(cfg, cg); got != tc.want {
+			t.Errorf("%s/%s got %v want %v", tc.topic, tc.subType, got, tc.want)
+		}
+	}
+}
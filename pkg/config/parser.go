@@ -7,7 +7,7 @@ import (
 	yaml "gopkg.in/yaml.v2"
 )
 
-// LoadFile reads a YAML config from disk and unmarshals it into Config.
+// LoadFile reads a YAML config from disk, unmarshals it into Config and expands templated topics.
 func LoadFile(path string) (*Config, error) {
 	b, err := os.ReadFile(path)
 	if err != nil {
@@ -17,5 +17,8 @@ func LoadFile(path string) (*Config, error) {
 	if err := yaml.Unmarshal(b, &cfg); err != nil {
 		return nil, fmt.Errorf("parse yaml: %w", err)
 	}
+	if err := Expand(&cfg); err != nil {
+		return nil, fmt.Errorf("expand config: %w", err)
+	}
 	return &cfg, nil
 }
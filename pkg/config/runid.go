@@ -0,0 +1,48 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// RunIDPlaceholder is substituted with the run ID in topic and subscription names.
+const RunIDPlaceholder = "{run_id}"
+
+// ApplyRunID resolves the run ID and substitutes it into topic and subscription names.
+// override (the --run-id flag) wins over execution.run_id; "auto" generates a fresh ID.
+// The resolved ID is stored back in execution.run_id; empty disables run stamping.
+func ApplyRunID(cfg *Config, override string) {
+	id := cfg.Execution.RunID
+	if override != "" {
+		id = override
+	}
+	if id == "auto" {
+		id = NewRunID()
+	}
+	cfg.Execution.RunID = id
+	if id == "" {
+		return
+	}
+	for i := range cfg.Topics {
+		cfg.Topics[i].Name = strings.ReplaceAll(cfg.Topics[i].Name, RunIDPlaceholder, id)
+	}
+	for i := range cfg.Producers {
+		cfg.Producers[i].Topic = strings.ReplaceAll(cfg.Producers[i].Topic, RunIDPlaceholder, id)
+	}
+	for i := range cfg.Consumers {
+		cfg.Consumers[i].Topic = strings.ReplaceAll(cfg.Consumers[i].Topic, RunIDPlaceholder, id)
+		cfg.Consumers[i].Subscription = strings.ReplaceAll(cfg.Consumers[i].Subscription, RunIDPlaceholder, id)
+	}
+	for i := range cfg.Thresholds {
+		cfg.Thresholds[i].Topic = strings.ReplaceAll(cfg.Thresholds[i].Topic, RunIDPlaceholder, id)
+	}
+}
+
+// NewRunID returns a sortable, practically unique run ID such as 20250101-120000-9f3a.
+func NewRunID() string {
+	b := make([]byte, 2)
+	_, _ = rand.Read(b)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func runIDConfig(runID string) *Config {
+	return &Config{
+		Execution:  ExecutionConfig{RunID: runID},
+		Topics:     []Topic{{Name: "/default/load_{run_id}"}},
+		Producers:  []ProducerGroup{{Topic: "/default/load_{run_id}"}},
+		Consumers:  []ConsumerGroup{{Topic: "/default/load_{run_id}", Subscription: "sub_{run_id}"}},
+		Thresholds: []Threshold{{Metric: "loss", Topic: "/default/load_{run_id}"}},
+	}
+}
+
+func TestApplyRunID(t *testing.T) {
+	cfg := runIDConfig("nightly")
+	ApplyRunID(cfg, "")
+	if cfg.Topics[0].Name != "/default/load_nightly" || cfg.Producers[0].Topic != "/default/load_nightly" {
+		t.Fatalf("topic not substituted: %+v %+v", cfg.Topics[0], cfg.Producers[0])
+	}
+	if c := cfg.Consumers[0]; c.Topic != "/default/load_nightly" || c.Subscription != "sub_nightly" {
+		t.Fatalf("consumer not substituted: %+v", c)
+	}
+	if th := cfg.Thresholds[0]; th.Topic != "/default/load_nightly" {
+		t.Fatalf("threshold topic not substituted: %+v", th)
+	}
+}
+
+func TestApplyRunIDOverrideAndAuto(t *testing.T) {
+	cfg := runIDConfig("nightly")
+	ApplyRunID(cfg, "auto")
+	if cfg.Execution.RunID == "" || cfg.Execution.RunID == "auto" || cfg.Execution.RunID == "nightly" {
+		t.Fatalf("expected a generated run ID, got %q", cfg.Execution.RunID)
+	}
+	if !strings.HasSuffix(cfg.Topics[0].Name, cfg.Execution.RunID) {
+		t.Fatalf("topic %q does not carry run ID %q", cfg.Topics[0].Name, cfg.Execution.RunID)
+	}
+}
+
+func TestApplyRunIDDisabled(t *testing.T) {
+	cfg := runIDConfig("")
+	ApplyRunID(cfg, "")
+	if cfg.Topics[0].Name != "/default/load_{run_id}" {
+		t.Fatalf("names must be left alone without a run ID, got %q", cfg.Topics[0].Name)
+	}
+	if errs := Validate(cfg); !containsError(errs, "execution.run_id") {
+		t.Fatalf("expected a run_id validation error, got %v", errs)
+	}
+}
+
+func containsError(errs []error, substr string) bool {
+	for _, e := range errs {
+		if strings.Contains(e.Error(), substr) {
+			return true
+		}
+	}
+	return false
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"strings"
+	"time"
 )
 
 // Validate performs basic schema validation and returns a list of errors (if any).
@@ -17,6 +19,26 @@ func Validate(cfg *Config) []error {
 	if cfg.Execution.Duration == "" {
 		errs = append(errs, fmt.Errorf("execution.duration is required"))
 	}
+	if rc := cfg.Danube.Reconnect; rc != nil {
+		if rc.InitialBackoff != "" && !positiveDuration(rc.InitialBackoff) {
+			errs = append(errs, fmt.Errorf("danube.reconnect.initial_backoff must be a positive duration"))
+		}
+		if rc.MaxBackoff != "" && !positiveDuration(rc.MaxBackoff) {
+			errs = append(errs, fmt.Errorf("danube.reconnect.max_backoff must be a positive duration"))
+		}
+		if rc.Multiplier != 0 && rc.Multiplier < 1 {
+			errs = append(errs, fmt.Errorf("danube.reconnect.multiplier must be >= 1"))
+		}
+	}
+	if m := cfg.Execution.Mode; m != "" && m != "traffic" && m != "idle" {
+		errs = append(errs, fmt.Errorf("execution.mode must be one of traffic|idle"))
+	}
+	if cfg.Execution.ReadyTimeout != "" && !positiveDuration(cfg.Execution.ReadyTimeout) {
+		errs = append(errs, fmt.Errorf("execution.ready_timeout must be a positive duration"))
+	}
+	if cfg.Execution.RunID == "" && usesRunID(cfg) {
+		errs = append(errs, fmt.Errorf("names use %s but execution.run_id is not set (or pass --run-id)", RunIDPlaceholder))
+	}
 
 	// Topics must have valid schema types
 	allowedSchemas := map[string]bool{"json": true, "string": true, "int64": true, "number": true}
@@ -40,7 +62,12 @@ func Validate(cfg *Config) []error {
 	}
 
 	// Producers
+	producerNames := map[string]int{}
 	for i, p := range cfg.Producers {
+		if j, ok := producerNames[p.Name]; ok && p.Name != "" {
+			errs = append(errs, fmt.Errorf("producers[%d].name %q is already used by producers[%d]", i, p.Name, j))
+		}
+		producerNames[p.Name] = i
 		if p.Topic == "" {
 			errs = append(errs, fmt.Errorf("producers[%d].topic is required", i))
 		}
@@ -53,11 +80,49 @@ func Validate(cfg *Config) []error {
 		if p.MessageSize < 0 {
 			errs = append(errs, fmt.Errorf("producers[%d].message_size must be >= 0", i))
 		}
+		if rk := p.RoutingKey; rk != nil {
+			// the key generator accepts any case, so the validator does too
+			dist := strings.ToLower(rk.Distribution)
+			switch dist {
+			case "uniform", "zipf", "sequential":
+				if rk.Keys <= 0 {
+					errs = append(errs, fmt.Errorf("producers[%d].routing_key.keys must be > 0", i))
+				}
+			case "hot":
+				if rk.HotPercent > 0 && rk.HotPercent < 100 && rk.Keys <= 0 {
+					errs = append(errs, fmt.Errorf("producers[%d].routing_key.keys must be > 0 when hot_percent < 100", i))
+				}
+				if rk.HotPercent < 0 || rk.HotPercent > 100 {
+					errs = append(errs, fmt.Errorf("producers[%d].routing_key.hot_percent must be within 0..100", i))
+				}
+			default:
+				errs = append(errs, fmt.Errorf("producers[%d].routing_key.distribution must be one of uniform|zipf|sequential|hot", i))
+			}
+			if dist == "zipf" && rk.ZipfSkew <= 1 {
+				errs = append(errs, fmt.Errorf("producers[%d].routing_key.zipf_skew must be > 1", i))
+			}
+		}
+		if ch := p.Churn; ch != nil {
+			if ch.RecreateEveryMessages < 0 {
+				errs = append(errs, fmt.Errorf("producers[%d].churn.recreate_every_messages must be >= 0", i))
+			}
+			if ch.RecreateEvery != "" && !positiveDuration(ch.RecreateEvery) {
+				errs = append(errs, fmt.Errorf("producers[%d].churn.recreate_every must be a positive duration", i))
+			}
+			if ch.RecreateEveryMessages == 0 && ch.RecreateEvery == "" {
+				errs = append(errs, fmt.Errorf("producers[%d].churn requires recreate_every_messages or recreate_every", i))
+			}
+		}
 	}
 
 	// Consumers
 	allowedSubs := map[string]bool{"shared": true, "exclusive": true, "failover": true}
+	consumerNames := map[string]int{}
 	for i, c := range cfg.Consumers {
+		if j, ok := consumerNames[c.Name]; ok && c.Name != "" {
+			errs = append(errs, fmt.Errorf("consumers[%d].name %q is already used by consumers[%d]", i, c.Name, j))
+		}
+		consumerNames[c.Name] = i
 		if c.Topic == "" {
 			errs = append(errs, fmt.Errorf("consumers[%d].topic is required", i))
 		}
@@ -70,6 +135,102 @@ func Validate(cfg *Config) []error {
 		if c.Count <= 0 {
 			errs = append(errs, fmt.Errorf("consumers[%d].count must be > 0", i))
 		}
+		// pkg/workload matches modes case-insensitively; accept what it accepts
+		switch strings.ToLower(c.AckMode) {
+		case "", "immediate", "never":
+		case "delayed":
+			if !positiveDuration(c.AckDelay) {
+				errs = append(errs, fmt.Errorf("consumers[%d].ack_delay must be a positive duration when ack_mode=delayed", i))
+			}
+		case "every_n":
+			if c.AckEvery <= 0 {
+				errs = append(errs, fmt.Errorf("consumers[%d].ack_every must be > 0 when ack_mode=every_n", i))
+			}
+		case "drop":
+			if c.AckDropPercent < 0 || c.AckDropPercent > 100 {
+				errs = append(errs, fmt.Errorf("consumers[%d].ack_drop_percent must be within 0..100", i))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("consumers[%d].ack_mode must be one of immediate|delayed|every_n|never|drop", i))
+		}
+		if pt := c.ProcessingTime; pt != nil {
+			switch strings.ToLower(pt.Mode) {
+			case "fixed", "cpu", "exponential":
+				if !positiveDuration(pt.Duration) {
+					errs = append(errs, fmt.Errorf("consumers[%d].processing_time.duration must be a positive duration", i))
+				}
+			case "normal":
+				if !positiveDuration(pt.Duration) {
+					errs = append(errs, fmt.Errorf("consumers[%d].processing_time.duration must be a positive duration", i))
+				}
+				if pt.Stddev != "" && !validDuration(pt.Stddev) {
+					errs = append(errs, fmt.Errorf("consumers[%d].processing_time.stddev must be a duration", i))
+				}
+			case "uniform":
+				if !validDuration(pt.Min) || !validDuration(pt.Max) {
+					errs = append(errs, fmt.Errorf("consumers[%d].processing_time.min and max must be durations", i))
+				} else if lo, hi := mustDuration(pt.Min), mustDuration(pt.Max); lo > hi {
+					errs = append(errs, fmt.Errorf("consumers[%d].processing_time.min must not exceed max", i))
+				}
+			default:
+				errs = append(errs, fmt.Errorf("consumers[%d].processing_time.mode must be one of fixed|uniform|normal|exponential|cpu", i))
+			}
+			if pt.Concurrency < 0 {
+				errs = append(errs, fmt.Errorf("consumers[%d].processing_time.concurrency must be >= 0", i))
+			}
+		}
+		if ch := c.Churn; ch != nil {
+			if ch.Mode != "restart" && ch.Mode != "kill_active" {
+				errs = append(errs, fmt.Errorf("consumers[%d].churn.mode must be one of restart|kill_active", i))
+			}
+			if !positiveDuration(ch.Interval) {
+				errs = append(errs, fmt.Errorf("consumers[%d].churn.interval must be a positive duration", i))
+			}
+			if ch.RestartDelay != "" && !validDuration(ch.RestartDelay) {
+				errs = append(errs, fmt.Errorf("consumers[%d].churn.restart_delay must be a duration", i))
+			}
+		}
+	}
+
+	// Thresholds
+	allowedOps := map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "==": true, "!=": true}
+	// groups and topics as the run reports them; unnamed groups are named after their index
+	groups := map[string]map[string]bool{"producer": {}, "consumer": {}}
+	topics := map[string]bool{}
+	for i, p := range cfg.Producers {
+		groups["producer"][groupOrIndex(p.Name, "producer", i)] = true
+		topics[p.Topic] = true
+	}
+	for i, c := range cfg.Consumers {
+		groups["consumer"][groupOrIndex(c.Name, "consumer", i)] = true
+		topics[c.Topic] = true
+	}
+	for _, t := range cfg.Topics {
+		topics[t.Name] = true
+	}
+	for i, th := range cfg.Thresholds {
+		if !ThresholdMetrics[th.Metric] {
+			errs = append(errs, fmt.Errorf("thresholds[%d].metric %q is not supported", i, th.Metric))
+		}
+		if !allowedOps[th.Op] {
+			errs = append(errs, fmt.Errorf("thresholds[%d].op must be one of < <= > >= == !=", i))
+		}
+		if th.Role != "" && th.Role != "producer" && th.Role != "consumer" {
+			errs = append(errs, fmt.Errorf("thresholds[%d].role must be one of producer|consumer", i))
+		}
+		if th.Group != "" {
+			role := thresholdGroupRole(th)
+			if ok := (groups["producer"][th.Group] && role != "consumer") || (groups["consumer"][th.Group] && role != "producer"); !ok {
+				kind := "producer or consumer"
+				if role != "" {
+					kind = role
+				}
+				errs = append(errs, fmt.Errorf("thresholds[%d].group %q is not a configured %s group", i, th.Group, kind))
+			}
+		}
+		if th.Topic != "" && !topics[th.Topic] {
+			errs = append(errs, fmt.Errorf("thresholds[%d].topic %q is not a configured topic", i, th.Topic))
+		}
 	}
 
 	// Metrics
@@ -82,5 +243,65 @@ func Validate(cfg *Config) []error {
 		}
 	}
 
+	if cfg.Metrics.HTMLReport && cfg.Metrics.ExportPath == "" {
+		errs = append(errs, fmt.Errorf("metrics.export_path is required when metrics.html_report=true"))
+	}
+	if cfg.Metrics.CSV && cfg.Metrics.ExportPath == "" {
+		errs = append(errs, fmt.Errorf("metrics.export_path is required when metrics.csv=true"))
+	}
+
 	return errs
 }
+
+// validDuration reports whether s parses as a non-negative time.Duration.
+func validDuration(s string) bool {
+	d, err := time.ParseDuration(s)
+	return err == nil && d >= 0
+}
+
+// mustDuration parses s, which the caller has already checked with validDuration.
+func mustDuration(s string) time.Duration {
+	d, _ := time.ParseDuration(s)
+	return d
+}
+
+// groupOrIndex returns name, or <role>-<i> for an unnamed group as the pools name it.
+func groupOrIndex(name, role string, i int) string {
+	if name == "" {
+		return fmt.Sprintf("%s-%d", role, i)
+	}
+	return name
+}
+
+// thresholdGroupRole returns the kind of group th's group must name: consumer for latency and
+// receive throughput, producer for send throughput, and th.Role (possibly either) for errors.
+func thresholdGroupRole(th Threshold) string {
+	switch th.Metric {
+	case "latency_p50_ms", "latency_p95_ms", "latency_p99_ms", "latency_max_ms", "throughput_recv":
+		return "consumer"
+	case "throughput_sent", "throughput_percent_of_target":
+		return "producer"
+	}
+	return th.Role
+}
+
+// positiveDuration reports whether s parses as a time.Duration greater than zero.
+func positiveDuration(s string) bool {
+	d, err := time.ParseDuration(s)
+	return err == nil && d > 0
+}
+
+// usesRunID reports whether any topic or subscription name still contains the run ID placeholder.
+func usesRunID(cfg *Config) bool {
+	for _, t := range cfg.Topics {
+		if strings.Contains(t.Name, RunIDPlaceholder) {
+			return true
+		}
+	}
+	for _, c := range cfg.Consumers {
+		if strings.Contains(c.Subscription, RunIDPlaceholder) {
+			return true
+		}
+	}
+	return false
+}
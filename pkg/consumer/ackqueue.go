@@ -0,0 +1,110 @@
+package consumer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ackQueue runs the delayed acks of one consumer session on a single goroutine and timer.
+// Every ack of a group waits the same ack_delay, so enqueue order is due order.
+type ackQueue struct {
+	mu      sync.Mutex
+	pending []delayedAck
+	closed  bool // stopped: pushes are skipped right away
+	done    bool // finishing: run returns once nothing is pending
+	wake    chan struct{}
+}
+
+type delayedAck struct {
+	due  time.Time
+	ack  func() // runs once due
+	skip func() // runs instead when the session ends first
+}
+
+func newAckQueue() *ackQueue {
+	return &ackQueue{wake: make(chan struct{}, 1)}
+}
+
+// push schedules ack to run after delay. Once the queue has stopped, skip runs right away.
+func (q *ackQueue) push(delay time.Duration, ack, skip func()) {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		skip()
+		return
+	}
+	q.pending = append(q.pending, delayedAck{due: time.Now().Add(delay), ack: ack, skip: skip})
+	q.mu.Unlock()
+	q.signal()
+}
+
+// finish makes run return once the pending acks are done; nothing may be pushed afterwards.
+func (q *ackQueue) finish() {
+	q.mu.Lock()
+	q.done = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *ackQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run acks pending messages as they come due until finish is called and nothing is pending,
+// or until ctx is done, after which the rest is skipped.
+func (q *ackQueue) run(ctx context.Context) {
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+	for {
+		q.mu.Lock()
+		var next delayedAck
+		ok, done := len(q.pending) > 0, q.done
+		if ok {
+			next = q.pending[0]
+		}
+		q.mu.Unlock()
+		if !ok {
+			if done {
+				return
+			}
+			select {
+			case <-q.wake:
+				continue
+			case <-ctx.Done():
+				q.stop()
+				return
+			}
+		}
+		if wait := time.Until(next.due); wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				q.stop()
+				return
+			}
+		}
+		q.mu.Lock()
+		q.pending[0] = delayedAck{}
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+		next.ack()
+	}
+}
+
+// stop closes the queue and skips every ack still pending.
+func (q *ackQueue) stop() {
+	q.mu.Lock()
+	q.closed = true
+	left := q.pending
+	q.pending = nil
+	q.mu.Unlock()
+	for _, d := range left {
+		d.skip()
+	}
+}
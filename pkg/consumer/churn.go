@@ -0,0 +1,113 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/config"
+)
+
+// groupChurn periodically closes one consumer session of a group: round-robin in restart
+// mode, or the worker that received the latest message (the active failover consumer)
+// in kill_active mode. Closed workers resubscribe after restartDelay.
+type groupChurn struct {
+	mode         string
+	workers      int
+	interval     time.Duration
+	restartDelay time.Duration
+
+	active atomic.Int64 // worker index that received the latest message, -1 if none
+
+	mu      sync.Mutex
+	cancels map[int]context.CancelFunc // live sessions by worker index
+	killed  map[int]bool               // sessions closed by churn
+	next    int                        // restart mode cursor
+}
+
+func newGroupChurn(cc *config.ChurnConfig, workers int) (*groupChurn, error) {
+	interval, err := time.ParseDuration(cc.Interval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid churn.interval %q", cc.Interval)
+	}
+	delay := time.Second
+	if cc.RestartDelay != "" {
+		if delay, err = time.ParseDuration(cc.RestartDelay); err != nil {
+			return nil, fmt.Errorf("invalid churn.restart_delay: %w", err)
+		}
+	}
+	g := &groupChurn{
+		mode:         cc.Mode,
+		workers:      max(workers, 1),
+		interval:     interval,
+		restartDelay: delay,
+		cancels:      make(map[int]context.CancelFunc),
+		killed:       make(map[int]bool),
+	}
+	g.active.Store(-1)
+	return g, nil
+}
+
+// attach registers the cancel function of worker idx's current session.
+func (g *groupChurn) attach(idx int, cancel context.CancelFunc) {
+	g.mu.Lock()
+	g.cancels[idx] = cancel
+	g.mu.Unlock()
+}
+
+// detach removes worker idx's session and reports whether churn closed it.
+func (g *groupChurn) detach(idx int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.cancels, idx)
+	killed := g.killed[idx]
+	delete(g.killed, idx)
+	return killed
+}
+
+// touch marks worker idx as the one that received the latest message.
+func (g *groupChurn) touch(idx int) { g.active.Store(int64(idx)) }
+
+// run fires a churn event every interval until ctx is done; onKill is called with the victim.
+func (g *groupChurn) run(ctx context.Context, onKill func(idx int)) {
+	t := time.NewTicker(g.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if idx, ok := g.kill(); ok {
+				onKill(idx)
+			}
+		}
+	}
+}
+
+// kill closes the victim's session, if it is live.
+func (g *groupChurn) kill() (int, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	victim := -1
+	switch g.mode {
+	case "kill_active":
+		victim = int(g.active.Load())
+	default: // restart: next live worker in index order
+		for i := 0; i < g.workers && victim < 0; i++ {
+			if _, ok := g.cancels[g.next]; ok && !g.killed[g.next] {
+				victim = g.next
+			}
+			g.next = (g.next + 1) % g.workers
+		}
+	}
+	cancel, ok := g.cancels[victim]
+	if !ok || g.killed[victim] {
+		return 0, false
+	}
+	g.killed[victim] = true
+	g.active.CompareAndSwap(int64(victim), -1)
+	cancel()
+	return victim, true
+}
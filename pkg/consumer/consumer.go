@@ -13,6 +13,8 @@ import (
 
 	"github.com/danube-messaging/loadtest_danube/pkg/config"
 	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/utils"
+	"github.com/danube-messaging/loadtest_danube/pkg/workload"
 )
 
 type Pool struct {
@@ -26,39 +28,204 @@ func NewPool(serviceURL string, cfg *config.Config, m *metrics.Collector) *Pool
 }
 
 // Start launches consumers for all consumer groups.
-func (p *Pool) Start(ctx context.Context, wg *sync.WaitGroup) {
-	for _, cg := range p.cfg.Consumers {
+// Each worker reports to ready once its first subscription succeeded or failed;
+// a nil ready is ignored.
+func (p *Pool) Start(ctx context.Context, wg *sync.WaitGroup, ready *utils.Barrier) {
+	for gi, cg := range p.cfg.Consumers {
+		group := groupName(cg, gi)
+		withheld := false
+		if acks, err := newAckPolicy(cg, 0); err == nil {
+			withheld = acks.WithholdsAcks()
+		}
+		p.metrics.RegisterSubscription(metrics.SubscriptionSpec{
+			Topic:        cg.Topic,
+			Subscription: cg.Subscription,
+			Ordered:      config.ImpliesOrdering(p.cfg, cg),
+			Reliable:     reliable(p.cfg, cg.Topic),
+			AcksWithheld: withheld,
+		})
+		// seed every configured partition so idle ones count in the skew
+		if t := config.FindTopic(p.cfg, cg.Topic); t != nil && t.Partitions > 0 {
+			p.metrics.RegisterPartitions(cg.Topic, t.Partitions)
+		}
+		concurrency := 1
+		if pt := cg.ProcessingTime; pt != nil && pt.Concurrency > 1 {
+			concurrency = pt.Concurrency
+		}
+		p.metrics.RegisterConsumerGroup(metrics.ConsumerGroupSpec{
+			Name:        group,
+			Workers:     cg.Count,
+			Concurrency: concurrency,
+			AckMode:     strings.ToLower(cg.AckMode),
+		})
+		var churn *groupChurn
+		if cg.Churn != nil {
+			var err error
+			if churn, err = newGroupChurn(cg.Churn, cg.Count); err != nil {
+				log.Printf("consumer churn config error: %v", err)
+				p.metrics.RecordError(metrics.ErrConfig, metrics.SetupConsumer, group, err)
+			} else {
+				wg.Add(1)
+				go func(group string) {
+					defer wg.Done()
+					churn.run(ctx, func(idx int) {
+						p.metrics.RecordChurnKill(group, workerName(group, idx))
+					})
+				}(group)
+			}
+		}
 		for i := 0; i < cg.Count; i++ {
 			wg.Add(1)
-			go func(group config.ConsumerGroup, workerIdx int) {
+			go func(cg config.ConsumerGroup, workerIdx int) {
 				defer wg.Done()
-				p.runWorker(ctx, group, workerIdx)
+				p.runWorker(ctx, cg, group, workerIdx, churn, ready)
 			}(cg, i)
 		}
 	}
 }
 
-func (p *Pool) runWorker(ctx context.Context, cg config.ConsumerGroup, idx int) {
-	subType := mapSubType(cg.SubscriptionType)
+// worker holds the per-worker state that survives resubscription.
+type worker struct {
+	cg          config.ConsumerGroup
+	idx         int
+	group       string
+	name        string
+	client      *danube.DanubeClient
+	partitioned bool
+	proc        *workload.Processor
+	sem         chan struct{}
+	acks        *workload.AckPolicy
+	churn       *groupChurn
+	// singleActive is set for failover and exclusive subscriptions, where one consumer receives
+	// at a time and a churn kill hands the stream to another worker
+	singleActive bool
+	runID        string
+	ready        *utils.Barrier
+
+	// setup covers every attempt until the first subscription; resubscriptions after churn or
+	// a lost stream are not setup cost
+	subscribed    bool
+	setupStart    time.Time
+	setupAttempts int
+	setupErr      error
+	subscribedAt  time.Time
+	awaitFirst    bool
+}
+
+func (p *Pool) runWorker(ctx context.Context, cg config.ConsumerGroup, group string, idx int, churn *groupChurn, ready *utils.Barrier) {
+	w := &worker{
+		cg:     cg,
+		idx:    idx,
+		group:  group,
+		client: danube.NewClient().ServiceURL(p.serviceURL).Build(),
+		churn:  churn,
+		runID:  p.cfg.Execution.RunID,
+		ready:  ready,
+	}
+	w.name = workerName(w.group, idx)
+	switch strings.ToLower(cg.SubscriptionType) {
+	case "failover", "exclusive":
+		w.singleActive = true
+	}
+	// a worker that never subscribed must not hold the readiness barrier
+	w.setupStart = time.Now()
+	defer func() {
+		if !w.subscribed {
+			if w.setupErr == nil {
+				w.setupErr = ctx.Err()
+			}
+			if w.setupAttempts > 0 {
+				p.metrics.RecordSetup(metrics.SetupConsumer, w.group, time.Since(w.setupStart), w.setupAttempts, w.setupErr)
+			}
+			ready.Fail()
+		}
+	}()
+
+	// Partitioned topics record which partition each message came from,
+	// using the partition topic name carried in the message id.
+	if t := config.FindTopic(p.cfg, cg.Topic); t != nil {
+		w.partitioned = t.Partitions > 0
+	}
+
+	// Optional simulated processing before the ack; with concurrency > 1 up to that many
+	// messages are processed in parallel by this worker.
+	var err error
+	if pt := cg.ProcessingTime; pt != nil {
+		w.proc, err = newProcessor(pt, time.Now().UnixNano()+int64(idx))
+		if err != nil {
+			log.Printf("consumer processing_time error: %v", err)
+			p.metrics.RecordError(metrics.ErrConfig, metrics.SetupConsumer, w.group, err)
+			return
+		}
+		if pt.Concurrency > 1 {
+			w.sem = make(chan struct{}, pt.Concurrency)
+		}
+	}
+	w.acks, err = newAckPolicy(cg, time.Now().UnixNano()+int64(idx))
+	if err != nil {
+		log.Printf("consumer ack_mode error: %v", err)
+		p.metrics.RecordError(metrics.ErrConfig, metrics.SetupConsumer, w.group, err)
+		return
+	}
 
-	client := danube.NewClient().ServiceURL(p.serviceURL).Build()
-	baseName := cg.Name
-	if baseName == "" {
-		baseName = "consumer"
+	// optional reconnect: resubscribe with exponential backoff after the stream closes or subscribe
+	// fails, including the first subscription
+	reconnect := p.cfg.Danube.Reconnect != nil
+	backoff := utils.NewBackoff(p.cfg.Danube.Reconnect)
+	for {
+		sessCtx, cancel := context.WithCancel(ctx)
+		if churn != nil {
+			churn.attach(idx, cancel)
+		}
+		subscribed := p.runSession(sessCtx, w)
+		cancel()
+		churned := churn != nil && churn.detach(idx)
+		if ctx.Err() != nil {
+			return
+		}
+		switch {
+		case churned:
+			// closed by churn: stay down for the restart delay, then resubscribe
+			if !utils.Sleep(ctx, churn.restartDelay) {
+				return
+			}
+		case reconnect:
+			// downtime runs from the first failure until a later session subscribes again;
+			// before the first subscription retries are setup, not downtime
+			if w.subscribed {
+				p.metrics.RecordDisconnect(metrics.SetupConsumer, w.group, w.name)
+			}
+			if subscribed {
+				backoff.Reset()
+			}
+			if !utils.Sleep(ctx, backoff.Next()) {
+				return
+			}
+		default:
+			return
+		}
 	}
-	consName := fmt.Sprintf("%s-%d", baseName, idx)
-	builder := client.NewConsumer(ctx).
-		WithConsumerName(consName).
+}
+
+// runSession subscribes once and consumes until ctx is done or the stream closes.
+// It reports whether the subscription succeeded.
+func (p *Pool) runSession(ctx context.Context, w *worker) (subscribed bool) {
+	cg, idx, group := w.cg, w.idx, w.group
+	builder := w.client.NewConsumer(ctx).
+		WithConsumerName(w.name).
 		WithTopic(cg.Topic).
 		WithSubscription(cg.Subscription).
-		WithSubscriptionType(subType)
+		WithSubscriptionType(mapSubType(cg.SubscriptionType))
 
 	cons, err := builder.Build()
 	if err != nil {
 		log.Printf("consumer build error: %v", err)
-		p.metrics.IncError(1)
+		p.metrics.RecordError(metrics.ErrBuild, metrics.SetupConsumer, group, err)
 		return
 	}
+	// close the consumer when the session ends so the broker drops it before the worker
+	// resubscribes; a churn kill then looks like a real consumer failure to the broker
+	defer p.closeConsumer(w, cons)
 	// Retry subscribe briefly to handle races where topic is not fully created yet
 	{
 		const (
@@ -70,6 +237,9 @@ func (p *Pool) runWorker(ctx context.Context, cg config.ConsumerGroup, idx int)
 			if ctx.Err() != nil {
 				return
 			}
+			if !w.subscribed {
+				w.setupAttempts++
+			}
 			if subErr = cons.Subscribe(ctx); subErr == nil {
 				break
 			}
@@ -80,18 +250,41 @@ func (p *Pool) runWorker(ctx context.Context, cg config.ConsumerGroup, idx int)
 		}
 		if subErr != nil {
 			log.Printf("consumer subscribe failed after retries: %v", subErr)
-			p.metrics.IncError(1)
+			p.metrics.RecordError(metrics.ErrSubscribe, metrics.SetupConsumer, group, subErr)
+			w.setupErr = subErr
 			return
 		}
+		subscribed = true
+		if !w.subscribed {
+			p.metrics.RecordSetup(metrics.SetupConsumer, group, time.Since(w.setupStart), w.setupAttempts, nil)
+			w.subscribed, w.subscribedAt, w.awaitFirst = true, time.Now(), true
+			w.ready.Ready()
+		} else {
+			p.metrics.RecordReconnect(metrics.SetupConsumer, group, w.name)
+		}
 	}
 
 	stream, err := cons.Receive(ctx)
 	if err != nil {
 		log.Printf("consumer receive error: %v", err)
-		p.metrics.IncError(1)
+		p.metrics.RecordError(metrics.ErrReceive, metrics.SetupConsumer, group, err)
 		return
 	}
 
+	// delayed acks do not hold the worker; one queue per session completes them in the background
+	var inflight sync.WaitGroup
+	delayed := newAckQueue()
+	queueDone := make(chan struct{})
+	go func() {
+		defer close(queueDone)
+		delayed.run(ctx)
+	}()
+	defer func() {
+		inflight.Wait()
+		delayed.finish()
+		<-queueDone
+	}()
+
 	// Latency no longer depends on schema; use PublishTime only
 	for {
 		select {
@@ -101,32 +294,177 @@ func (p *Pool) runWorker(ctx context.Context, cg config.ConsumerGroup, idx int)
 			if !ok {
 				return
 			}
+			// backlog from other runs is acked but counted only as stale, outside received,
+			// throughput, latency and integrity stats
+			if w.runID != "" && msg.GetAttributes()["run"] != w.runID {
+				p.metrics.IncStale(1)
+				if _, err := cons.Ack(ctx, msg); err != nil {
+					p.metrics.RecordError(metrics.ErrAck, metrics.SetupConsumer, group, err)
+				}
+				continue
+			}
+			if w.awaitFirst {
+				w.awaitFirst = false
+				p.metrics.RecordFirstMessage(group, time.Since(w.subscribedAt))
+			}
+			if w.churn != nil {
+				w.churn.touch(idx)
+			}
+			var partition string
+			if w.partitioned {
+				if partition = msg.GetMsgId().GetTopicName(); partition == "" {
+					partition = "unknown"
+				}
+				p.metrics.RecordPartitionReceive(cg.Topic, partition)
+			}
 			// Use broker/client PublishTime for E2E latency
 			nowMs := time.Now().UnixMilli()
 			if pub := msg.GetPublishTime(); pub > 0 {
 				lat := float64(nowMs) - float64(pub)
 				if lat >= 0 {
 					p.metrics.RecordLatency(lat)
+					p.metrics.RecordGroupLatency(group, lat)
+					if w.partitioned {
+						p.metrics.RecordPartitionLatency(cg.Topic, partition, lat)
+					}
 				}
 			}
 			// Track sequences per topic+subscription+producer using message attributes
+			var prod string
+			var seqVal uint64
+			tracked := false
 			if attrs := msg.GetAttributes(); attrs != nil {
 				if seqStr, ok := attrs["seq"]; ok {
-					if seqVal, err := strconv.ParseUint(seqStr, 10, 64); err == nil {
-						prod := attrs["producer"]
-						p.metrics.RecordSeq(cg.Topic, cg.Subscription, prod, seqVal)
+					if v, err := strconv.ParseUint(seqStr, 10, 64); err == nil {
+						seqVal, prod, tracked = v, attrs["producer"], true
+						p.metrics.RecordDelivery(cg.Topic, cg.Subscription, prod, w.name, seqVal)
+						// takeovers only exist where one consumer receives at a time
+						if w.churn != nil && w.singleActive {
+							p.metrics.ObserveChurn(group, w.name, prod, seqVal)
+						}
 					}
 				}
 			}
 			p.metrics.IncReceived(1)
-			if _, err := cons.Ack(ctx, msg); err != nil {
-				log.Printf("ack error topic=%s worker=%d: %v", cg.Topic, idx, err)
-				p.metrics.IncError(1)
+			// an unacked message is expected back, possibly on another consumer
+			unacked := func() {
+				if tracked {
+					p.metrics.RecordUnacked(cg.Topic, cg.Subscription, prod, seqVal)
+				}
+			}
+			ack := func() {
+				// at shutdown processing returns early and the session context is done;
+				// acking on it would only fail, so the ack is counted as skipped
+				if ctx.Err() != nil {
+					p.metrics.RecordAck(group, metrics.AckSkipped)
+					return
+				}
+				if _, err := cons.Ack(ctx, msg); err != nil {
+					log.Printf("ack error topic=%s worker=%d: %v", cg.Topic, idx, err)
+					p.metrics.RecordError(metrics.ErrAck, metrics.SetupConsumer, group, err)
+					p.metrics.RecordAck(group, metrics.AckFailed)
+					unacked()
+					return
+				}
+				p.metrics.RecordAck(group, metrics.AckAcked)
+			}
+			handle := func() {
+				start := time.Now()
+				if w.proc != nil {
+					w.proc.Process(ctx)
+				}
+				switch d := w.acks.Next(); {
+				case !d.Ack:
+					p.metrics.RecordAck(group, metrics.AckSkipped)
+					unacked()
+				case d.Delay > 0:
+					delayed.push(d.Delay, ack, func() {
+						p.metrics.RecordAck(group, metrics.AckSkipped)
+						unacked()
+					})
+				default:
+					ack()
+				}
+				p.metrics.RecordConsumed(group, time.Since(start))
+			}
+			if w.sem == nil {
+				handle()
+				continue
+			}
+			select {
+			case w.sem <- struct{}{}:
+			case <-ctx.Done():
+				return
 			}
+			inflight.Add(1)
+			go func() {
+				defer inflight.Done()
+				defer func() { <-w.sem }()
+				handle()
+			}()
 		}
 	}
 }
 
+// closeConsumer closes cons so the broker drops it from the subscription.
+func (p *Pool) closeConsumer(w *worker, cons *danube.Consumer) {
+	if err := cons.Close(); err != nil {
+		log.Printf("consumer close error worker=%s: %v", w.name, err)
+	}
+}
+
+// workerName returns the consumer name of worker idx in group.
+func workerName(group string, idx int) string {
+	return fmt.Sprintf("%s-%d", group, idx)
+}
+
+// groupName returns the consumer group name used for worker names and per-group stats.
+// Unnamed groups are named after their index in consumers[] so their stats stay apart.
+func groupName(cg config.ConsumerGroup, i int) string {
+	if cg.Name == "" {
+		return fmt.Sprintf("consumer-%d", i)
+	}
+	return cg.Name
+}
+
+// newAckPolicy converts the group's validated ack settings into a workload.AckPolicy.
+func newAckPolicy(cg config.ConsumerGroup, seed int64) (*workload.AckPolicy, error) {
+	spec := workload.AckSpec{Mode: cg.AckMode, Every: cg.AckEvery, DropPercent: cg.AckDropPercent}
+	if cg.AckDelay != "" {
+		d, err := time.ParseDuration(cg.AckDelay)
+		if err != nil {
+			return nil, err
+		}
+		spec.Delay = d
+	}
+	return workload.NewAckPolicy(spec, seed)
+}
+
+// newProcessor converts a validated processing_time config into a workload.Processor.
+func newProcessor(pt *config.ProcessingTimeConfig, seed int64) (*workload.Processor, error) {
+	spec := workload.ProcessingSpec{Mode: pt.Mode}
+	for _, f := range []struct {
+		raw string
+		dst *time.Duration
+	}{{pt.Duration, &spec.Duration}, {pt.Min, &spec.Min}, {pt.Max, &spec.Max}, {pt.Stddev, &spec.Stddev}} {
+		if f.raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f.raw)
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = d
+	}
+	return workload.NewProcessor(spec, seed)
+}
+
+// reliable reports whether the topic uses reliable dispatch.
+func reliable(cfg *config.Config, topic string) bool {
+	t := config.FindTopic(cfg, topic)
+	return t != nil && t.DispatchStrategy == "reliable"
+}
+
 func mapSubType(s string) danube.SubType {
 	switch strings.ToLower(s) {
 	case "exclusive":
@@ -0,0 +1,135 @@
+// Package history keeps an append-only index of exported results so runs of a test can be
+// listed and compared over time.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
+)
+
+// IndexFile is the name of the index in the export directory, one JSON entry per line.
+const IndexFile = "history.jsonl"
+
+// Entry is one indexed run: its identity and the headline numbers used for trends.
+type Entry struct {
+	TestName         string            `json:"test_name"`
+	RunID            string            `json:"run_id,omitempty"`
+	ConfigHash       string            `json:"config_hash,omitempty"`
+	Labels           map[string]string `json:"labels,omitempty"`
+	StartedAt        time.Time         `json:"started_at"`
+	File             string            `json:"file"` // result JSON, relative to the index
+	DurationSec      float64           `json:"duration_sec"`
+	ThroughputSent   float64           `json:"throughput_sent"`
+	ThroughputRecv   float64           `json:"throughput_recv"`
+	LatencyP99Ms     float64           `json:"latency_p99_ms"`
+	Errors           uint64            `json:"errors"`
+	EstimatedLoss    uint64            `json:"estimated_loss"`
+	Duplicates       uint64            `json:"duplicates"`
+	ThresholdsFailed int               `json:"thresholds_failed"`
+}
+
+// NewEntry indexes r, stored at file.
+func NewEntry(r results.Result, file string) Entry {
+	s := r.Snapshot
+	return Entry{
+		TestName:         r.TestName,
+		RunID:            r.RunID,
+		ConfigHash:       r.ConfigHash,
+		Labels:           r.Labels,
+		StartedAt:        r.StartedAt,
+		File:             file,
+		DurationSec:      r.DurationSec,
+		ThroughputSent:   s.ThroughputSent,
+		ThroughputRecv:   s.ThroughputRecv,
+		LatencyP99Ms:     s.LatencyP99Ms,
+		Errors:           s.Errors,
+		EstimatedLoss:    s.EstimatedLoss,
+		Duplicates:       s.Duplicates,
+		ThresholdsFailed: thresholds.Failed(r.Thresholds),
+	}
+}
+
+// Append adds e to the index in dir, creating it if needed.
+func Append(dir string, e Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal history entry: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, IndexFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("append history: %w", err)
+	}
+	return f.Close()
+}
+
+// Load reads the index in dir, oldest run first. A missing index is empty; lines that do not
+// parse (e.g. a write cut short) are skipped and counted in skipped.
+func Load(dir string) (entries []Entry, skipped int, err error) {
+	f, err := os.Open(filepath.Join(dir, IndexFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("open history: %w", err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			skipped++
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, skipped, fmt.Errorf("read history: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].StartedAt.Before(entries[j].StartedAt) })
+	return entries, skipped, nil
+}
+
+// Query selects entries; empty fields match everything.
+type Query struct {
+	TestName   string
+	ConfigHash string
+	Labels     map[string]string // every label must match
+}
+
+// Filter returns the entries matching q, keeping their order.
+func Filter(entries []Entry, q Query) []Entry {
+	var out []Entry
+next:
+	for _, e := range entries {
+		if q.TestName != "" && e.TestName != q.TestName {
+			continue
+		}
+		if q.ConfigHash != "" && e.ConfigHash != q.ConfigHash {
+			continue
+		}
+		for k, v := range q.Labels {
+			if e.Labels[k] != v {
+				continue next
+			}
+		}
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package history
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
+)
+
+func TestAppendAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := results.Result{
+		TestName:   "soak",
+		RunID:      "r2",
+		ConfigHash: "abc",
+		Labels:     map[string]string{"broker": "0.5"},
+		StartedAt:  at.Add(time.Hour),
+		Snapshot:   metrics.Snapshot{ThroughputSent: 900, LatencyP99Ms: 12},
+		Thresholds: []thresholds.Verdict{{Pass: false}},
+	}
+	// appended out of order; Load sorts by start time
+	if err := Append(dir, NewEntry(r, "soak_2.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Append(dir, Entry{TestName: "soak", RunID: "r1", StartedAt: at, ThroughputSent: 1000}); err != nil {
+		t.Fatal(err)
+	}
+	// a torn line from an interrupted write is skipped
+	f, _ := os.OpenFile(filepath.Join(dir, IndexFile), os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"test_name":"so` + "\n")
+	f.Close()
+
+	entries, skipped, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 1 || len(entries) != 2 || entries[0].RunID != "r1" || entries[1].RunID != "r2" {
+		t.Fatalf("entries %+v skipped %d", entries, skipped)
+	}
+	e := entries[1]
+	if e.File != "soak_2.json" || e.ThresholdsFailed != 1 || e.Labels["broker"] != "0.5" || e.LatencyP99Ms != 12 {
+		t.Fatalf("entry not preserved: %+v", e)
+	}
+}
+
+func TestLoadMissingIndex(t *testing.T) {
+	entries, skipped, err := Load(t.TempDir())
+	if err != nil || skipped != 0 || entries != nil {
+		t.Fatalf("got %v %d %v", entries, skipped, err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	entries := []Entry{
+		{TestName: "soak", ConfigHash: "a", Labels: map[string]string{"broker": "0.4", "env": "ci"}},
+		{TestName: "soak", ConfigHash: "b", Labels: map[string]string{"broker": "0.5"}},
+		{TestName: "burst", ConfigHash: "a"},
+	}
+	if got := Filter(entries, Query{TestName: "soak"}); len(got) != 2 {
+		t.Fatalf("by test got %d", len(got))
+	}
+	if got := Filter(entries, Query{TestName: "soak", Labels: map[string]string{"broker": "0.4"}}); len(got) != 1 || got[0].ConfigHash != "a" {
+		t.Fatalf("by label got %+v", got)
+	}
+	if got := Filter(entries, Query{ConfigHash: "a"}); len(got) != 2 {
+		t.Fatalf("by hash got %d", len(got))
+	}
+}
+
+func TestTrendsAndWrite(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []Entry{
+		{RunID: "r1", ConfigHash: "a", StartedAt: at, ThroughputSent: 1000, LatencyP99Ms: 10},
+		{RunID: "r2", ConfigHash: "a", StartedAt: at.Add(time.Hour), ThroughputSent: 950, LatencyP99Ms: 11},
+		{RunID: "r3", ConfigHash: "b", StartedAt: at.Add(2 * time.Hour), ThroughputSent: 900, LatencyP99Ms: 15},
+	}
+	tr := Trends(entries)
+	if tr[0].Metric != "tx msg/s" || tr[0].ChangePercent != -10 || tr[0].Min != 900 || tr[0].Max != 1000 {
+		t.Fatalf("throughput trend %+v", tr[0])
+	}
+	if tr[2].Metric != "p99 ms" || tr[2].ChangePercent != 50 {
+		t.Fatalf("p99 trend %+v", tr[2])
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Trends over 3 runs", "change=-10.0%", "change=+50.0%", "b *"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output misses %q:\n%s", want, out)
+		}
+	}
+}
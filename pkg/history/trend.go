@@ -0,0 +1,94 @@
+package history
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/utils"
+)
+
+// Trend summarizes one metric across runs, oldest first.
+type Trend struct {
+	Metric        string
+	Values        []float64
+	First, Last   float64
+	Min, Max      float64
+	ChangePercent float64 // last against first; 0 when first is 0
+}
+
+// Trends returns throughput and p99 latency trends over entries.
+func Trends(entries []Entry) []Trend {
+	pick := func(name string, f func(Entry) float64) Trend {
+		t := Trend{Metric: name}
+		for _, e := range entries {
+			t.Values = append(t.Values, f(e))
+		}
+		if len(t.Values) == 0 {
+			return t
+		}
+		t.First, t.Last = t.Values[0], t.Values[len(t.Values)-1]
+		t.Min, t.Max = t.First, t.First
+		for _, v := range t.Values {
+			t.Min, t.Max = min(t.Min, v), max(t.Max, v)
+		}
+		if t.First != 0 {
+			t.ChangePercent = (t.Last - t.First) * 100 / t.First
+		}
+		return t
+	}
+	return []Trend{
+		pick("tx msg/s", func(e Entry) float64 { return e.ThroughputSent }),
+		pick("rx msg/s", func(e Entry) float64 { return e.ThroughputRecv }),
+		pick("p99 ms", func(e Entry) float64 { return e.LatencyP99Ms }),
+	}
+}
+
+// Write prints the runs, oldest first, followed by the trend lines.
+func Write(w io.Writer, entries []Entry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STARTED\tRUN ID\tCONFIG\tLABELS\tTX MSG/S\tRX MSG/S\tP99 MS\tERRORS\tLOSS\tDUP\tTHRESHOLDS\t")
+	prevHash := ""
+	for i, e := range entries {
+		hash := e.ConfigHash
+		// a changed scenario breaks comparability; flag it where it happens
+		if i > 0 && hash != prevHash {
+			hash += " *"
+		}
+		prevHash = e.ConfigHash
+		th := "ok"
+		if e.ThresholdsFailed > 0 {
+			th = fmt.Sprintf("%d failed", e.ThresholdsFailed)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.1f\t%.1f\t%.1f\t%d\t%d\t%d\t%s\t\n",
+			e.StartedAt.Local().Format("2006-01-02 15:04"), e.RunID, hash, formatLabels(e.Labels),
+			e.ThroughputSent, e.ThroughputRecv, e.LatencyP99Ms, e.Errors, e.EstimatedLoss, e.Duplicates, th)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(entries) < 2 {
+		return nil
+	}
+	fmt.Fprintf(w, "\nTrends over %d runs (oldest to newest):\n", len(entries))
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, t := range Trends(entries) {
+		fmt.Fprintf(tw, "  %s\t%s\tfirst=%.1f\tlast=%.1f\tmin=%.1f\tmax=%.1f\tchange=%+.1f%%\n",
+			t.Metric, utils.Sparkline(t.Values), t.First, t.Last, t.Min, t.Max, t.ChangePercent)
+	}
+	return tw.Flush()
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	parts := make([]string, 0, len(labels))
+	for k, v := range labels {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
@@ -9,16 +9,56 @@ import (
 
 type Collector struct {
 	Start time.Time
+	// end of the traffic phase; send rates are measured up to it, receive rates include the drain
+	trafficEnd time.Time
 
 	MessagesSent     atomic.Uint64
 	MessagesReceived atomic.Uint64
 	Errors           atomic.Uint64
+	// messages stamped with another run ID, excluded from received, latency and integrity
+	StaleMessages atomic.Uint64
 
 	mu        sync.Mutex
 	latencies []float64 // milliseconds
 
 	// message tracking per topic+subscription+producer
 	trackers map[trackerKey]*seqTracker
+	// subscriptions registered by the consumer pool, with their delivery guarantees
+	subscriptions map[subscriptionKey]SubscriptionSpec
+	// producer-side sent ledger per topic+producer; used for loss once reconciled
+	ledger     map[ledgerKey]*sendLedger
+	reconciled bool
+
+	// successful sends per topic+routing key
+	keySends map[keySendKey]uint64
+
+	// receive counts and latencies per partition of partitioned topics
+	partitions map[partitionKey]*partitionTracker
+
+	// per consumer group receive counts and busy time
+	consumerGroups map[string]*consumerGroupTracker
+	// per producer group send counts and target rate
+	producerGroups map[string]*producerGroupTracker
+	// failover measurements for consumer groups with churn
+	failovers map[string]*failoverTracker
+	// producer.Create timings per producer group
+	producerCreates map[string]*producerCreateTracker
+	// initial producer create and subscribe cost per role+group
+	setup     map[setupKey]*setupTracker
+	readiness *ReadinessStats
+	// disconnects and downtime per role+group
+	reconnects map[setupKey]*reconnectTracker
+	// errors per category+role+group+code and distinct messages
+	errorCounts   map[errorKey]uint64
+	errorMessages map[messageKey]*messageTracker
+	// per report interval samples for time series
+	intervals    []IntervalSample
+	lastInterval intervalState
+}
+
+type keySendKey struct {
+	Topic string
+	Key   string
 }
 
 type trackerKey struct {
@@ -27,20 +67,45 @@ type trackerKey struct {
 	Producer     string
 }
 
-type seqTracker struct {
-	Min        uint64
-	Max        uint64
-	Seen       map[uint64]struct{}
-	Duplicates uint64
+type subscriptionKey struct {
+	Topic        string
+	Subscription string
+}
+
+// SubscriptionSpec describes what the config implies for a topic+subscription pair.
+type SubscriptionSpec struct {
+	Topic        string
+	Subscription string
+	// Ordered is set when the subscription type and topic layout guarantee per-producer order
+	Ordered bool
+	// Reliable is set for reliable dispatch, where redelivery after a failed ack is expected
+	Reliable bool
+	// AcksWithheld is set when a consumer group's ack mode leaves messages unacked on purpose
+	AcksWithheld bool
 }
 
 func NewCollector() *Collector {
-	return &Collector{Start: time.Now(), trackers: make(map[trackerKey]*seqTracker)}
+	return &Collector{
+		Start:           time.Now(),
+		trackers:        make(map[trackerKey]*seqTracker),
+		subscriptions:   make(map[subscriptionKey]SubscriptionSpec),
+		ledger:          make(map[ledgerKey]*sendLedger),
+		keySends:        make(map[keySendKey]uint64),
+		partitions:      make(map[partitionKey]*partitionTracker),
+		consumerGroups:  make(map[string]*consumerGroupTracker),
+		producerGroups:  make(map[string]*producerGroupTracker),
+		failovers:       make(map[string]*failoverTracker),
+		producerCreates: make(map[string]*producerCreateTracker),
+		setup:           make(map[setupKey]*setupTracker),
+		reconnects:      make(map[setupKey]*reconnectTracker),
+		errorCounts:     make(map[errorKey]uint64),
+		errorMessages:   make(map[messageKey]*messageTracker),
+	}
 }
 
 func (c *Collector) IncSent(n uint64)     { c.MessagesSent.Add(n) }
 func (c *Collector) IncReceived(n uint64) { c.MessagesReceived.Add(n) }
-func (c *Collector) IncError(n uint64)    { c.Errors.Add(n) }
+func (c *Collector) IncStale(n uint64)    { c.StaleMessages.Add(n) }
 
 // RecordLatency adds an end-to-end latency sample in milliseconds.
 func (c *Collector) RecordLatency(ms float64) {
@@ -49,70 +114,139 @@ func (c *Collector) RecordLatency(ms float64) {
 	c.mu.Unlock()
 }
 
+// RegisterSubscription declares a topic+subscription pair and its delivery guarantees.
+func (c *Collector) RegisterSubscription(spec SubscriptionSpec) {
+	k := subscriptionKey{Topic: spec.Topic, Subscription: spec.Subscription}
+	c.mu.Lock()
+	// several groups may share a subscription; any of them withholding acks causes redelivery
+	if prev, ok := c.subscriptions[k]; ok && prev.AcksWithheld {
+		spec.AcksWithheld = true
+	}
+	c.subscriptions[k] = spec
+	c.mu.Unlock()
+}
+
 // RecordSeq records observed sequence for a given topic+subscription+producer.
 func (c *Collector) RecordSeq(topic, subscription, producer string, seq uint64) {
+	c.RecordDelivery(topic, subscription, producer, "", seq)
+}
+
+// RecordDelivery records observed sequence for a given topic+subscription+producer,
+// tagged with the consumer that received it so duplicates can be classified.
+func (c *Collector) RecordDelivery(topic, subscription, producer, consumer string, seq uint64) {
 	k := trackerKey{Topic: topic, Subscription: subscription, Producer: producer}
+	now := time.Now()
 	c.mu.Lock()
 	t, ok := c.trackers[k]
 	if !ok {
-		t = &seqTracker{Min: seq, Max: seq, Seen: make(map[uint64]struct{})}
+		t = newSeqTracker()
 		c.trackers[k] = t
 	}
-	if seq < t.Min {
-		t.Min = seq
-	}
-	if seq > t.Max {
-		t.Max = seq
-	}
-	if _, exists := t.Seen[seq]; exists {
-		t.Duplicates++
-	} else {
-		t.Seen[seq] = struct{}{}
+	t.deliver(seq, consumer, now)
+	c.mu.Unlock()
+}
+
+// RecordUnacked records that the ack of seq delivered on topic+subscription from producer
+// failed or was skipped, so a later delivery of it to any consumer counts as a redelivery.
+func (c *Collector) RecordUnacked(topic, subscription, producer string, seq uint64) {
+	k := trackerKey{Topic: topic, Subscription: subscription, Producer: producer}
+	now := time.Now()
+	c.mu.Lock()
+	if t, ok := c.trackers[k]; ok {
+		t.unack(seq, now)
 	}
 	c.mu.Unlock()
 }
 
+// RecordKeySent counts a successful send carrying the given routing key.
+func (c *Collector) RecordKeySent(topic, key string) {
+	c.mu.Lock()
+	c.keySends[keySendKey{Topic: topic, Key: key}]++
+	c.mu.Unlock()
+}
+
+// SetStart moves the start of the measured period, e.g. to when traffic is released.
+// Unlike assigning Start, it is safe while other goroutines take snapshots.
+func (c *Collector) SetStart(t time.Time) {
+	c.mu.Lock()
+	c.Start = t
+	c.mu.Unlock()
+}
+
+// EndTraffic marks when producers were told to stop. Send rates are measured over
+// [Start, t] from then on, so a cooldown drain does not dilute them.
+func (c *Collector) EndTraffic(t time.Time) {
+	c.mu.Lock()
+	c.trafficEnd = t
+	c.mu.Unlock()
+}
+
 func (c *Collector) Snapshot() Snapshot {
+	// copy latencies to avoid holding lock during sort
+	c.mu.Lock()
 	elapsed := time.Since(c.Start).Seconds()
+	traffic := elapsed
+	if !c.trafficEnd.IsZero() {
+		traffic = min(c.trafficEnd.Sub(c.Start).Seconds(), elapsed)
+	}
 	sent := c.MessagesSent.Load()
 	recv := c.MessagesReceived.Load()
 	errs := c.Errors.Load()
-	// copy latencies to avoid holding lock during sort
-	c.mu.Lock()
 	lcopy := append([]float64(nil), c.latencies...)
 	// compute duplicate and loss estimates and build breakdown per key
-	var dup uint64
-	var loss uint64
+	var dup, loss, outOfOrder, violations uint64
 	var breakdown []IntegrityEntry
 	for k, t := range c.trackers {
-		entry := IntegrityEntry{
-			Topic:        k.Topic,
-			Subscription: k.Subscription,
-			Producer:     k.Producer,
-			Min:          t.Min,
-			Max:          t.Max,
-			UniqueSeen:   uint64(len(t.Seen)),
-			Duplicates:   t.Duplicates,
-		}
-		if t.Max >= t.Min {
-			expected := (t.Max - t.Min + 1)
-			if expected > entry.UniqueSeen {
-				entry.Loss = expected - entry.UniqueSeen
-			}
-		}
+		breakdown = append(breakdown, c.integrityEntryLocked(k, t))
+	}
+	if c.reconciled {
+		breakdown = append(breakdown, c.unseenProducersLocked()...)
+	}
+	for _, entry := range breakdown {
 		dup += entry.Duplicates
 		loss += entry.Loss
-		breakdown = append(breakdown, entry)
+		outOfOrder += entry.OutOfOrder
+		violations += entry.OrderingViolations
+	}
+	partitions := c.partitionStatsLocked()
+	consumerGroups := c.consumerGroupStatsLocked(elapsed)
+	producerGroups := c.producerGroupStatsLocked(traffic)
+	failovers := c.failoverStatsLocked()
+	producerCreates := c.producerCreateStatsLocked()
+	setup := c.setupStatsLocked()
+	readiness := c.readiness
+	reconnects := c.reconnectStatsLocked(time.Now())
+	errorCategories, errorBreakdown, topErrors := c.errorStatsLocked()
+	intervals := c.intervalsLocked()
+	var keySends []KeySendCount
+	for k, n := range c.keySends {
+		keySends = append(keySends, KeySendCount{Topic: k.Topic, Key: k.Key, Sent: n})
 	}
 	c.mu.Unlock()
+	// busiest keys first within each topic
+	sort.Slice(keySends, func(i, j int) bool {
+		if keySends[i].Topic != keySends[j].Topic {
+			return keySends[i].Topic < keySends[j].Topic
+		}
+		if keySends[i].Sent != keySends[j].Sent {
+			return keySends[i].Sent > keySends[j].Sent
+		}
+		return keySends[i].Key < keySends[j].Key
+	})
 	sort.Float64s(lcopy)
 	p50, p95, p99, pmax := percentiles(lcopy)
+	curve := latencyCurve(lcopy)
 	return Snapshot{
 		ElapsedSec:         elapsed,
+		TrafficSec:         traffic,
 		MessagesSent:       sent,
 		MessagesReceived:   recv,
 		Errors:             errs,
-		ThroughputSent:     rate(sent, elapsed),
+		ErrorCategories:    errorCategories,
+		ErrorBreakdown:     errorBreakdown,
+		TopErrors:          topErrors,
+		StaleMessages:      c.StaleMessages.Load(),
+		ThroughputSent:     rate(sent, traffic),
 		ThroughputRecv:     rate(recv, elapsed),
 		LatencyP50Ms:       p50,
 		LatencyP95Ms:       p95,
@@ -121,6 +255,55 @@ func (c *Collector) Snapshot() Snapshot {
 		LatencySamples:     len(lcopy),
 		Duplicates:         dup,
 		EstimatedLoss:      loss,
+		OutOfOrder:         outOfOrder,
+		OrderingViolations: violations,
 		IntegrityBreakdown: breakdown,
+		KeySends:           keySends,
+		Partitions:         partitions,
+		PartitionSkew:      partitionSkew(partitions),
+		ConsumerGroups:     consumerGroups,
+		ProducerGroups:     producerGroups,
+		Failovers:          failovers,
+		ProducerCreates:    producerCreates,
+		Setup:              setup,
+		Readiness:          readiness,
+		Reconnects:         reconnects,
+		Intervals:          intervals,
+		LatencyCurve:       curve,
+	}
+}
+
+// integrityEntryLocked builds the breakdown entry for one tracker; caller must hold c.mu.
+func (c *Collector) integrityEntryLocked(k trackerKey, t *seqTracker) IntegrityEntry {
+	entry := IntegrityEntry{
+		Topic:              k.Topic,
+		Subscription:       k.Subscription,
+		Producer:           k.Producer,
+		Min:                t.Min,
+		Max:                t.Max,
+		UniqueSeen:         t.Unique,
+		Loss:               t.Loss(),
+		Duplicates:         t.Duplicates,
+		LateArrivals:       t.Late,
+		Unverifiable:       t.Unverifiable,
+		OutOfOrder:         t.OutOfOrder,
+		MaxReorderDistance: t.MaxReorder,
+	}
+	spec := c.subscriptions[subscriptionKey{Topic: k.Topic, Subscription: k.Subscription}]
+	entry.OrderingExpected = spec.Ordered
+	entry.RedeliveryExpected = spec.Reliable
+	entry.AcksWithheld = spec.AcksWithheld
+	entry.Redeliveries = t.Redeliveries
+	entry.CrossConsumerDuplicates = t.CrossDuplicates
+	entry.UnclassifiedDuplicates = t.UnclassifiedDuplicates
+	entry.RedeliveryDelayMs = t.redeliveryDelays.summary()
+	if entry.OrderingExpected {
+		entry.OrderingViolations = entry.OutOfOrder
+	}
+	if c.reconciled {
+		if l, ok := c.ledger[ledgerKey{Topic: k.Topic, Producer: k.Producer}]; ok {
+			l.reconcile(&entry, t)
+		}
 	}
+	return entry
 }
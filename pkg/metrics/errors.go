@@ -0,0 +1,166 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Error categories, one per operation that can fail.
+const (
+	ErrConfig    = "config"
+	ErrBuild     = "build"
+	ErrCreate    = "create"
+	ErrSubscribe = "subscribe"
+	ErrReceive   = "receive"
+	ErrSend      = "send"
+	ErrAck       = "ack"
+)
+
+const (
+	// maxErrorMessages bounds the distinct messages kept for the top errors table;
+	// further distinct messages are folded into one "(other)" entry per category.
+	maxErrorMessages = 1000
+	maxMessageLen    = 200
+	topErrorsShown   = 10
+)
+
+type errorKey struct {
+	Category string
+	Role     string
+	Group    string
+	Code     string
+}
+
+type messageKey struct {
+	Category string
+	Message  string
+}
+
+type messageTracker struct {
+	count       uint64
+	first, last time.Time
+}
+
+// ErrorCount is the number of errors for one category, group and status code.
+type ErrorCount struct {
+	Category string `json:"category"`
+	Role     string `json:"role"` // producer|consumer
+	Group    string `json:"group"`
+	Code     string `json:"code"` // gRPC status code, or Canceled/DeadlineExceeded/Unknown
+	Count    uint64 `json:"count"`
+}
+
+// ErrorMessage is one distinct error message with its count and first/last occurrence.
+type ErrorMessage struct {
+	Category string    `json:"category"`
+	Message  string    `json:"message"`
+	Count    uint64    `json:"count"`
+	First    time.Time `json:"first"`
+	Last     time.Time `json:"last"`
+}
+
+// RecordError counts an error under its category and the role+group it happened in,
+// classified by gRPC status code, and keeps its message for the top errors table.
+func (c *Collector) RecordError(category, role, group string, err error) {
+	c.Errors.Add(1)
+	now := time.Now()
+	code, msg := classifyError(err)
+	c.mu.Lock()
+	c.errorCounts[errorKey{Category: category, Role: role, Group: group, Code: code}]++
+	mk := messageKey{Category: category, Message: msg}
+	m, ok := c.errorMessages[mk]
+	if !ok {
+		if len(c.errorMessages) >= maxErrorMessages {
+			mk.Message = "(other)"
+			m = c.errorMessages[mk]
+		}
+		if m == nil {
+			m = &messageTracker{first: now}
+			c.errorMessages[mk] = m
+		}
+	}
+	m.count++
+	m.last = now
+	c.mu.Unlock()
+}
+
+// classifyError extracts the gRPC status code from "rpc error: code = X desc = Y" messages
+// and returns it with a trimmed message.
+func classifyError(err error) (code, msg string) {
+	if err == nil {
+		return "Unknown", "unknown error"
+	}
+	msg = err.Error()
+	code = "Unknown"
+	if i := strings.Index(msg, "rpc error: code = "); i >= 0 {
+		rest := msg[i+len("rpc error: code = "):]
+		if j := strings.IndexByte(rest, ' '); j >= 0 {
+			code = rest[:j]
+		} else {
+			code = rest
+		}
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		code = "DeadlineExceeded"
+	} else if errors.Is(err, context.Canceled) {
+		code = "Canceled"
+	}
+	if len(msg) > maxMessageLen {
+		msg = msg[:maxMessageLen] + "..."
+	}
+	return code, msg
+}
+
+// errorStatsLocked builds per-category totals, the per group and code breakdown and
+// the most frequent messages; caller must hold c.mu.
+// errorCategoryTotalsLocked sums the errors recorded per category; caller must hold c.mu.
+func (c *Collector) errorCategoryTotalsLocked() map[string]uint64 {
+	totals := make(map[string]uint64)
+	for k, n := range c.errorCounts {
+		totals[k.Category] += n
+	}
+	return totals
+}
+
+func (c *Collector) errorStatsLocked() (map[string]uint64, []ErrorCount, []ErrorMessage) {
+	if len(c.errorCounts) == 0 {
+		return nil, nil, nil
+	}
+	categories := make(map[string]uint64)
+	var counts []ErrorCount
+	for k, n := range c.errorCounts {
+		categories[k.Category] += n
+		counts = append(counts, ErrorCount{Category: k.Category, Role: k.Role, Group: k.Group, Code: k.Code, Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].Category != counts[j].Category {
+			return counts[i].Category < counts[j].Category
+		}
+		if counts[i].Role != counts[j].Role {
+			return counts[i].Role < counts[j].Role
+		}
+		if counts[i].Group != counts[j].Group {
+			return counts[i].Group < counts[j].Group
+		}
+		return counts[i].Code < counts[j].Code
+	})
+	var top []ErrorMessage
+	for k, m := range c.errorMessages {
+		top = append(top, ErrorMessage{Category: k.Category, Message: k.Message, Count: m.count, First: m.first, Last: m.last})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].First.Before(top[j].First)
+	})
+	if len(top) > topErrorsShown {
+		top = top[:topErrorsShown]
+	}
+	return categories, counts, top
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestRecordErrorCategories(t *testing.T) {
+	c := NewCollector()
+	unavailable := errors.New("rpc error: code = Unavailable desc = connection refused")
+	c.RecordError(ErrSend, SetupProducer, "pubs", unavailable)
+	c.RecordError(ErrSend, SetupProducer, "pubs", unavailable)
+	c.RecordError(ErrAck, SetupConsumer, "subs", fmt.Errorf("ack: %w", context.DeadlineExceeded))
+	c.RecordError(ErrSubscribe, SetupConsumer, "subs", errors.New("topic not found"))
+
+	snap := c.Snapshot()
+	if snap.Errors != 4 {
+		t.Fatalf("errors got %d want 4", snap.Errors)
+	}
+	if snap.ErrorCategories[ErrSend] != 2 || snap.ErrorCategories[ErrAck] != 1 || snap.ErrorCategories[ErrSubscribe] != 1 {
+		t.Fatalf("unexpected categories: %v", snap.ErrorCategories)
+	}
+	want := []ErrorCount{
+		{Category: ErrSend, Role: SetupProducer, Group: "pubs", Code: "Unavailable", Count: 2},
+		{Category: ErrAck, Role: SetupConsumer, Group: "subs", Code: "DeadlineExceeded", Count: 1},
+		{Category: ErrSubscribe, Role: SetupConsumer, Group: "subs", Code: "Unknown", Count: 1},
+	}
+	if len(snap.ErrorBreakdown) != len(want) {
+		t.Fatalf("breakdown got %+v", snap.ErrorBreakdown)
+	}
+	for i, w := range want {
+		if snap.ErrorBreakdown[i] != w {
+			t.Errorf("breakdown[%d] got %+v want %+v", i, snap.ErrorBreakdown[i], w)
+		}
+	}
+	top := snap.TopErrors[0]
+	if top.Message != unavailable.Error() || top.Count != 2 || top.Last.Before(top.First) {
+		t.Fatalf("unexpected top error: %+v", top)
+	}
+}
+
+func TestRecordErrorBoundsMessages(t *testing.T) {
+	c := NewCollector()
+	for i := 0; i < maxErrorMessages+50; i++ {
+		c.RecordError(ErrSend, SetupProducer, "pubs", fmt.Errorf("send seq=%d failed", i))
+	}
+	if len(c.errorMessages) > maxErrorMessages+1 {
+		t.Fatalf("kept %d distinct messages, want at most %d", len(c.errorMessages), maxErrorMessages+1)
+	}
+	if n := c.errorMessages[messageKey{Category: ErrSend, Message: "(other)"}].count; n != 50 {
+		t.Fatalf("(other) got %d want 50", n)
+	}
+}
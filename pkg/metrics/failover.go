@@ -0,0 +1,137 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// failoverTracker measures, for one consumer group, how long it takes another consumer
+// to take over after a churn kill and how many messages are lost or duplicated across the switch.
+type failoverTracker struct {
+	last    map[string]uint64 // highest sequence per producer received by the group
+	pending *failoverEvent
+	kills   int
+	gaps    []float64 // milliseconds
+	lost    uint64
+	dups    uint64
+}
+
+type failoverEvent struct {
+	killed     string
+	at         time.Time
+	before     map[string]uint64 // last per producer at kill time
+	tookOver   bool
+	firstAfter map[string]uint64 // first sequence per producer received after the switch
+}
+
+// FailoverStats summarizes churn kills and takeovers for a consumer group. Takeovers and
+// the messages lost or duplicated across them are only measured on failover and exclusive
+// subscriptions; on shared subscriptions other workers receive all along.
+type FailoverStats struct {
+	Group      string       `json:"group"`
+	Kills      int          `json:"kills"`
+	Takeovers  int          `json:"takeovers"`
+	GapMs      Distribution `json:"gap_ms"` // kill until another consumer received a message
+	Lost       uint64       `json:"lost"`
+	Duplicated uint64       `json:"duplicated"`
+}
+
+// RecordChurnKill notes that worker of group was closed by churn at the current time.
+func (c *Collector) RecordChurnKill(group, worker string) {
+	now := time.Now()
+	c.mu.Lock()
+	c.failover(group).kill(worker, now)
+	c.mu.Unlock()
+}
+
+// ObserveChurn feeds a message received by worker of a churned group into failover tracking.
+func (c *Collector) ObserveChurn(group, worker, producer string, seq uint64) {
+	now := time.Now()
+	c.mu.Lock()
+	c.failover(group).observe(worker, producer, seq, now)
+	c.mu.Unlock()
+}
+
+// failover returns the tracker for group; caller must hold c.mu.
+func (c *Collector) failover(group string) *failoverTracker {
+	f, ok := c.failovers[group]
+	if !ok {
+		f = &failoverTracker{last: make(map[string]uint64)}
+		c.failovers[group] = f
+	}
+	return f
+}
+
+func (f *failoverTracker) kill(worker string, now time.Time) {
+	f.finish()
+	f.kills++
+	before := make(map[string]uint64, len(f.last))
+	for p, s := range f.last {
+		before[p] = s
+	}
+	f.pending = &failoverEvent{killed: worker, at: now, before: before, firstAfter: make(map[string]uint64)}
+}
+
+func (f *failoverTracker) observe(worker, producer string, seq uint64, now time.Time) {
+	if ev := f.pending; ev != nil && worker != ev.killed {
+		if !ev.tookOver {
+			ev.tookOver = true
+			f.gaps = append(f.gaps, float64(now.Sub(ev.at).Microseconds())/1000)
+		}
+		if _, ok := ev.firstAfter[producer]; !ok {
+			ev.firstAfter[producer] = seq
+		}
+	}
+	if seq > f.last[producer] {
+		f.last[producer] = seq
+	}
+}
+
+// finish settles the pending event: per producer, compare the first sequence received
+// after the switch with the last one received before it.
+func (f *failoverTracker) finish() {
+	ev := f.pending
+	if ev == nil {
+		return
+	}
+	f.pending = nil
+	for p, first := range ev.firstAfter {
+		before, ok := ev.before[p]
+		if !ok {
+			continue
+		}
+		switch {
+		case first > before+1:
+			f.lost += first - before - 1
+		case first <= before:
+			f.dups += before - first + 1
+		}
+	}
+}
+
+// failoverStatsLocked builds per-group failover stats; caller must hold c.mu.
+func (c *Collector) failoverStatsLocked() []FailoverStats {
+	var out []FailoverStats
+	for group, f := range c.failovers {
+		if f.kills == 0 {
+			continue
+		}
+		// settle a copy so that an open event still shows up without being closed early
+		cp := *f
+		if f.pending != nil {
+			ev := *f.pending
+			cp.pending = &ev
+		}
+		cp.finish()
+		out = append(out, FailoverStats{
+			Group:      group,
+			Kills:      f.kills,
+			Takeovers:  len(f.gaps),
+			GapMs:      summarize(f.gaps),
+			Lost:       cp.lost,
+			Duplicated: cp.dups,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Group < out[j].Group })
+	return out
+}
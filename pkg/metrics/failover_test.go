@@ -0,0 +1,49 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFailoverTracker_GapLossAndDuplicates(t *testing.T) {
+	f := &failoverTracker{last: make(map[string]uint64)}
+	t0 := time.Now()
+	f.observe("c-0", "p1", 1, t0)
+	f.observe("c-0", "p1", 2, t0)
+	f.observe("c-0", "p2", 10, t0)
+
+	f.kill("c-0", t0.Add(time.Second))
+	f.observe("c-0", "p1", 3, t0.Add(1100*time.Millisecond)) // in-flight on the killed worker, ignored
+	f.observe("c-1", "p1", 5, t0.Add(1500*time.Millisecond)) // takeover: 3 and 4 lost from c-1's view
+	f.observe("c-1", "p2", 9, t0.Add(1600*time.Millisecond)) // 9 and 10 delivered again
+
+	// a second kill settles the first event
+	f.kill("c-1", t0.Add(2*time.Second))
+
+	if f.kills != 2 || len(f.gaps) != 1 {
+		t.Fatalf("kills/takeovers got %d/%d want 2/1", f.kills, len(f.gaps))
+	}
+	if f.gaps[0] != 500 {
+		t.Fatalf("gap got %vms want 500ms", f.gaps[0])
+	}
+	// p1 last seen before the kill was 2 (3 arrived on the killed worker), so 3..4 are lost
+	if f.lost != 2 || f.dups != 2 {
+		t.Fatalf("lost/dup got %d/%d want 2/2", f.lost, f.dups)
+	}
+}
+
+func TestFailoverStatsInSnapshot(t *testing.T) {
+	c := NewCollector()
+	c.ObserveChurn("g", "c-0", "p", 1)
+	c.RecordChurnKill("g", "c-0")
+	c.ObserveChurn("g", "c-1", "p", 2)
+
+	snap := c.Snapshot()
+	if len(snap.Failovers) != 1 {
+		t.Fatalf("failovers size got %d want 1", len(snap.Failovers))
+	}
+	fs := snap.Failovers[0]
+	if fs.Group != "g" || fs.Kills != 1 || fs.Takeovers != 1 || fs.GapMs.Count != 1 || fs.Lost != 0 || fs.Duplicated != 0 {
+		t.Fatalf("unexpected failover stats: %+v", fs)
+	}
+}
@@ -0,0 +1,184 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+type consumerGroupTracker struct {
+	ConsumerGroupSpec
+	Received uint64
+	Busy     time.Duration
+	Acks     [numAckOutcomes]uint64
+	// end-to-end latency of messages received by this group, in milliseconds, sampled
+	latencies reservoir
+}
+
+// ConsumerGroupSpec describes a consumer group as configured.
+type ConsumerGroupSpec struct {
+	Name        string
+	Workers     int
+	Concurrency int // messages processed in parallel per worker
+	AckMode     string
+}
+
+// AckOutcome is what happened to one received message's acknowledgement.
+type AckOutcome int
+
+const (
+	AckAcked   AckOutcome = iota // acked successfully
+	AckFailed                    // ack attempted and failed
+	AckSkipped                   // left unacked on purpose by the ack mode, or because the run ended first
+	numAckOutcomes
+)
+
+// ConsumerGroupStats holds per consumer group receive counts and utilization
+type ConsumerGroupStats struct {
+	Name     string  `json:"name"`
+	Workers  int     `json:"workers"`
+	Slots    int     `json:"slots"`
+	Received uint64  `json:"received"`
+	BusySec  float64 `json:"busy_sec"`
+	// Utilization is busy time (processing plus ack) over elapsed time across all slots
+	Utilization float64 `json:"utilization"`
+	AckMode     string  `json:"ack_mode"`
+	Acked       uint64  `json:"acked"`
+	AckFailed   uint64  `json:"ack_failed"`
+	AckSkipped  uint64  `json:"ack_skipped"`
+	// LatencyMs is the end-to-end latency of messages received by this group
+	LatencyMs Distribution `json:"latency_ms"`
+}
+
+// RegisterConsumerGroup declares a consumer group with its worker count, concurrency and ack mode.
+func (c *Collector) RegisterConsumerGroup(spec ConsumerGroupSpec) {
+	if spec.Concurrency < 1 {
+		spec.Concurrency = 1
+	}
+	if spec.AckMode == "" {
+		spec.AckMode = "immediate"
+	}
+	c.mu.Lock()
+	c.consumerGroup(spec.Name).ConsumerGroupSpec = spec
+	c.mu.Unlock()
+}
+
+// RecordAck counts the ack outcome of one message in a consumer group.
+func (c *Collector) RecordAck(group string, outcome AckOutcome) {
+	c.mu.Lock()
+	c.consumerGroup(group).Acks[outcome]++
+	c.mu.Unlock()
+}
+
+// RecordConsumed counts a message handled by a consumer group and the time it kept a slot busy.
+func (c *Collector) RecordConsumed(group string, busy time.Duration) {
+	c.mu.Lock()
+	g := c.consumerGroup(group)
+	g.Received++
+	g.Busy += busy
+	c.mu.Unlock()
+}
+
+// RecordGroupLatency adds an end-to-end latency sample in milliseconds for a consumer group.
+func (c *Collector) RecordGroupLatency(group string, ms float64) {
+	c.mu.Lock()
+	g := c.consumerGroup(group)
+	g.latencies.add(ms)
+	c.mu.Unlock()
+}
+
+// consumerGroup returns the tracker for a group; caller must hold c.mu.
+func (c *Collector) consumerGroup(name string) *consumerGroupTracker {
+	g, ok := c.consumerGroups[name]
+	if !ok {
+		g = &consumerGroupTracker{}
+		c.consumerGroups[name] = g
+	}
+	return g
+}
+
+// consumerGroupStatsLocked builds per-group stats sorted by name; caller must hold c.mu.
+func (c *Collector) consumerGroupStatsLocked(elapsedSec float64) []ConsumerGroupStats {
+	var out []ConsumerGroupStats
+	for name, g := range c.consumerGroups {
+		st := ConsumerGroupStats{
+			Name:       name,
+			Workers:    g.Workers,
+			Slots:      g.Workers * g.Concurrency,
+			Received:   g.Received,
+			BusySec:    g.Busy.Seconds(),
+			AckMode:    g.AckMode,
+			Acked:      g.Acks[AckAcked],
+			AckFailed:  g.Acks[AckFailed],
+			AckSkipped: g.Acks[AckSkipped],
+			LatencyMs:  g.latencies.summary(),
+		}
+		if st.Slots > 0 && elapsedSec > 0 {
+			st.Utilization = st.BusySec / (elapsedSec * float64(st.Slots))
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+type producerGroupTracker struct {
+	ProducerGroupSpec
+	Sent uint64
+}
+
+// ProducerGroupSpec describes a producer group as configured.
+type ProducerGroupSpec struct {
+	Name          string
+	Workers       int
+	RatePerSecond int // per worker; 0 means unlimited
+}
+
+// ProducerGroupStats holds per producer group send counts and achieved throughput
+type ProducerGroupStats struct {
+	Name           string  `json:"name"`
+	Workers        int     `json:"workers"`
+	Sent           uint64  `json:"sent"`
+	ThroughputSent float64 `json:"throughput_sent"`
+	// TargetRate is workers x rate_per_second, 0 when unlimited
+	TargetRate float64 `json:"target_rate"`
+}
+
+// RegisterProducerGroup declares a producer group with its worker count and target rate.
+func (c *Collector) RegisterProducerGroup(spec ProducerGroupSpec) {
+	c.mu.Lock()
+	c.producerGroup(spec.Name).ProducerGroupSpec = spec
+	c.mu.Unlock()
+}
+
+// RecordGroupSent counts a successful send by a producer group.
+func (c *Collector) RecordGroupSent(group string) {
+	c.mu.Lock()
+	c.producerGroup(group).Sent++
+	c.mu.Unlock()
+}
+
+// producerGroup returns the tracker for a group; caller must hold c.mu.
+func (c *Collector) producerGroup(name string) *producerGroupTracker {
+	g, ok := c.producerGroups[name]
+	if !ok {
+		g = &producerGroupTracker{ProducerGroupSpec: ProducerGroupSpec{Name: name}}
+		c.producerGroups[name] = g
+	}
+	return g
+}
+
+// producerGroupStatsLocked builds per-group stats sorted by name; caller must hold c.mu.
+func (c *Collector) producerGroupStatsLocked(elapsedSec float64) []ProducerGroupStats {
+	var out []ProducerGroupStats
+	for name, g := range c.producerGroups {
+		out = append(out, ProducerGroupStats{
+			Name:           name,
+			Workers:        g.Workers,
+			Sent:           g.Sent,
+			ThroughputSent: rate(g.Sent, elapsedSec),
+			TargetRate:     float64(g.Workers * g.RatePerSecond),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConsumerGroupUtilization(t *testing.T) {
+	c := NewCollector()
+	c.RegisterConsumerGroup(ConsumerGroupSpec{Name: "slow", Workers: 2, Concurrency: 2})
+	c.RecordConsumed("slow", 300*time.Millisecond)
+	c.RecordConsumed("slow", 100*time.Millisecond)
+	c.Start = time.Now().Add(-1 * time.Second)
+
+	snap := c.Snapshot()
+	if len(snap.ConsumerGroups) != 1 {
+		t.Fatalf("consumer groups size got %d want 1", len(snap.ConsumerGroups))
+	}
+	g := snap.ConsumerGroups[0]
+	if g.Name != "slow" || g.Workers != 2 || g.Slots != 4 || g.Received != 2 {
+		t.Fatalf("unexpected group stats: %+v", g)
+	}
+	// 0.4s busy over ~1s elapsed across 4 slots => ~10%
+	if g.Utilization < 0.09 || g.Utilization > 0.101 {
+		t.Fatalf("utilization got %v want ~0.10", g.Utilization)
+	}
+}
+
+func TestConsumerGroupAckOutcomes(t *testing.T) {
+	c := NewCollector()
+	c.RegisterConsumerGroup(ConsumerGroupSpec{Name: "g", Workers: 1, AckMode: "drop"})
+	c.RecordAck("g", AckAcked)
+	c.RecordAck("g", AckAcked)
+	c.RecordAck("g", AckSkipped)
+	c.RecordAck("g", AckFailed)
+
+	g := c.Snapshot().ConsumerGroups[0]
+	if g.AckMode != "drop" || g.Acked != 2 || g.AckSkipped != 1 || g.AckFailed != 1 {
+		t.Fatalf("unexpected ack outcomes: %+v", g)
+	}
+}
+
+func TestGroupLatencyAndProducerGroups(t *testing.T) {
+	c := NewCollector()
+	c.RegisterConsumerGroup(ConsumerGroupSpec{Name: "subs", Workers: 1})
+	for _, ms := range []float64{1, 2, 3, 40} {
+		c.RecordGroupLatency("subs", ms)
+	}
+	c.RegisterProducerGroup(ProducerGroupSpec{Name: "pubs", Workers: 2, RatePerSecond: 50})
+	for i := 0; i < 100; i++ {
+		c.RecordGroupSent("pubs")
+	}
+	c.Start = time.Now().Add(-1 * time.Second)
+
+	snap := c.Snapshot()
+	if l := snap.ConsumerGroups[0].LatencyMs; l.Count != 4 || l.Max != 40 || l.P50 != 3 {
+		t.Fatalf("group latency got %+v", l)
+	}
+	pg := snap.ProducerGroups[0]
+	if pg.Name != "pubs" || pg.Sent != 100 || pg.TargetRate != 100 {
+		t.Fatalf("unexpected producer group: %+v", pg)
+	}
+	if pg.ThroughputSent < 95 || pg.ThroughputSent > 100.1 {
+		t.Fatalf("throughput got %v want ~100", pg.ThroughputSent)
+	}
+}
+
+func TestSendThroughputExcludesDrain(t *testing.T) {
+	got := map[time.Duration]float64{}
+	for _, drain := range []time.Duration{0, 10 * time.Second} {
+		c := NewCollector()
+		c.RegisterProducerGroup(ProducerGroupSpec{Name: "pubs", Workers: 1, RatePerSecond: 50})
+		for i := 0; i < 100; i++ {
+			c.IncSent(1)
+			c.RecordGroupSent("pubs")
+		}
+		end := time.Now().Add(-drain)
+		c.SetStart(end.Add(-2 * time.Second))
+		c.EndTraffic(end)
+		snap := c.Snapshot()
+		if snap.ThroughputSent != snap.ProducerGroups[0].ThroughputSent {
+			t.Fatalf("drain %s: run and group send rates differ: %v vs %v", drain, snap.ThroughputSent, snap.ProducerGroups[0].ThroughputSent)
+		}
+		got[drain] = snap.ThroughputSent
+	}
+	if got[0] != 50 || got[10*time.Second] != 50 {
+		t.Fatalf("send throughput got %v, want 50 msg/s regardless of the drain", got)
+	}
+}
+
+func TestGroupLatencyBounded(t *testing.T) {
+	c := NewCollector()
+	c.RegisterConsumerGroup(ConsumerGroupSpec{Name: "subs", Workers: 1})
+	for i := 1; i <= 3*reservoirSize; i++ {
+		c.RecordGroupLatency("subs", float64(i))
+	}
+	if n := len(c.consumerGroups["subs"].latencies.samples); n > reservoirSize {
+		t.Fatalf("latency samples grew to %d", n)
+	}
+	if l := c.Snapshot().ConsumerGroups[0].LatencyMs; l.Count != 3*reservoirSize || l.Max != 3*reservoirSize {
+		t.Fatalf("count and max must stay exact: %+v", l)
+	}
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// IntervalSample holds the rates and latency of one reporting interval, for time series charts.
+type IntervalSample struct {
+	At         time.Time `json:"at"`
+	ElapsedSec float64   `json:"elapsed_sec"`
+	// cumulative counters at the end of the interval
+	Sent     uint64 `json:"sent"`
+	Received uint64 `json:"received"`
+	Errors   uint64 `json:"errors"`
+	// rates over the interval
+	TxRate    float64 `json:"tx_rate"`
+	RxRate    float64 `json:"rx_rate"`
+	ErrorRate float64 `json:"error_rate"`
+	// ErrorRates is the error rate per category, for categories with errors in the interval
+	ErrorRates map[string]float64 `json:"error_rates,omitempty"`
+	// latency of the messages received during the interval
+	LatencyMs Distribution `json:"latency_ms"`
+}
+
+// PercentilePoint is one point of the latency percentile curve.
+type PercentilePoint struct {
+	Percentile float64 `json:"percentile"`
+	Ms         float64 `json:"ms"`
+}
+
+// curvePercentiles are the points of the latency percentile curve in Snapshot.LatencyCurve.
+var curvePercentiles = []float64{0, 10, 25, 50, 75, 90, 95, 99, 99.9, 99.99, 100}
+
+// intervalState remembers where the previous interval ended.
+type intervalState struct {
+	at                     time.Time
+	sent, received, errors uint64
+	categories             map[string]uint64 // errors per category
+	latencies              int               // index into Collector.latencies
+}
+
+// SampleInterval closes the interval started at the previous call (or at Start) and
+// records its rates and latency. The runner calls it on every report tick.
+func (c *Collector) SampleInterval(now time.Time) IntervalSample {
+	sent := c.MessagesSent.Load()
+	recv := c.MessagesReceived.Load()
+	errs := c.Errors.Load()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prev := c.lastInterval
+	if prev.at.IsZero() {
+		prev.at = c.Start
+	}
+	secs := now.Sub(prev.at).Seconds()
+	s := IntervalSample{
+		At:         now,
+		ElapsedSec: now.Sub(c.Start).Seconds(),
+		Sent:       sent,
+		Received:   recv,
+		Errors:     errs,
+		TxRate:     rate(sent-prev.sent, secs),
+		RxRate:     rate(recv-prev.received, secs),
+		ErrorRate:  rate(errs-prev.errors, secs),
+		LatencyMs:  summarize(c.latencies[prev.latencies:]),
+	}
+	categories := c.errorCategoryTotalsLocked()
+	for cat, n := range categories {
+		if n > prev.categories[cat] {
+			if s.ErrorRates == nil {
+				s.ErrorRates = make(map[string]float64)
+			}
+			s.ErrorRates[cat] = rate(n-prev.categories[cat], secs)
+		}
+	}
+	c.intervals = append(c.intervals, s)
+	c.lastInterval = intervalState{at: now, sent: sent, received: recv, errors: errs, categories: categories, latencies: len(c.latencies)}
+	return s
+}
+
+// FormatRates formats per-category rates as cat=rate pairs sorted by category and joined by sep,
+// e.g. "ack=0.4 send=1.2".
+func FormatRates(rates map[string]float64, sep string) string {
+	cats := make([]string, 0, len(rates))
+	for cat := range rates {
+		cats = append(cats, cat)
+	}
+	sort.Strings(cats)
+	parts := make([]string, len(cats))
+	for i, cat := range cats {
+		parts[i] = fmt.Sprintf("%s=%.1f", cat, rates[cat])
+	}
+	return strings.Join(parts, sep)
+}
+
+// latencyCurve returns the latency at each of curvePercentiles; sorted must be ascending.
+func latencyCurve(sorted []float64) []PercentilePoint {
+	n := len(sorted)
+	if n == 0 {
+		return nil
+	}
+	out := make([]PercentilePoint, 0, len(curvePercentiles))
+	for _, p := range curvePercentiles {
+		k := int(p/100*float64(n-1) + 0.5)
+		if k >= n {
+			k = n - 1
+		}
+		out = append(out, PercentilePoint{Percentile: p, Ms: sorted[k]})
+	}
+	return out
+}
+
+// intervalsLocked returns a copy of the recorded samples in time order; caller must hold c.mu.
+func (c *Collector) intervalsLocked() []IntervalSample {
+	out := append([]IntervalSample(nil), c.intervals...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].At.Before(out[j].At) })
+	return out
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSampleInterval(t *testing.T) {
+	c := NewCollector()
+	start := c.Start
+	c.IncSent(100)
+	c.IncReceived(50)
+	c.RecordLatency(10)
+	c.RecordLatency(20)
+	first := c.SampleInterval(start.Add(10 * time.Second))
+	if first.TxRate != 10 || first.RxRate != 5 || first.LatencyMs.Count != 2 || first.LatencyMs.Max != 20 {
+		t.Fatalf("first interval %+v", first)
+	}
+
+	c.IncSent(20)
+	for i := 0; i < 3; i++ {
+		c.RecordError(ErrSend, SetupProducer, "pubs", errors.New("unavailable"))
+	}
+	c.RecordError(ErrAck, SetupConsumer, "subs", errors.New("timeout"))
+	c.RecordLatency(5)
+	second := c.SampleInterval(start.Add(12 * time.Second))
+	if second.TxRate != 10 || second.RxRate != 0 || second.ErrorRate != 2 || second.Sent != 120 {
+		t.Fatalf("second interval %+v", second)
+	}
+	if r := second.ErrorRates; len(r) != 2 || r[ErrSend] != 1.5 || r[ErrAck] != 0.5 {
+		t.Fatalf("second interval error rates %v", r)
+	}
+	third := c.SampleInterval(start.Add(14 * time.Second))
+	if third.ErrorRates != nil {
+		t.Fatalf("interval without errors has rates %v", third.ErrorRates)
+	}
+	// only latencies recorded since the previous sample
+	if second.LatencyMs.Count != 1 || second.LatencyMs.Max != 5 {
+		t.Fatalf("second interval latency %+v", second.LatencyMs)
+	}
+	if got := len(c.Snapshot().Intervals); got != 3 {
+		t.Fatalf("snapshot intervals got %d want 3", got)
+	}
+}
+
+func TestLatencyCurve(t *testing.T) {
+	c := NewCollector()
+	for i := 1; i <= 100; i++ {
+		c.RecordLatency(float64(i))
+	}
+	curve := c.Snapshot().LatencyCurve
+	if len(curve) != len(curvePercentiles) {
+		t.Fatalf("curve points got %d want %d", len(curve), len(curvePercentiles))
+	}
+	if first, last := curve[0], curve[len(curve)-1]; first.Ms != 1 || last.Percentile != 100 || last.Ms != 100 {
+		t.Fatalf("curve ends %+v %+v", first, last)
+	}
+	for i := 1; i < len(curve); i++ {
+		if curve[i].Ms < curve[i-1].Ms {
+			t.Fatalf("curve not monotonic at %d: %+v", i, curve)
+		}
+	}
+	if NewCollector().Snapshot().LatencyCurve != nil {
+		t.Fatal("empty collector should have no curve")
+	}
+}
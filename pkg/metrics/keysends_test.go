@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestRecordKeySent_CountsPerTopicAndKey(t *testing.T) {
+	c := NewCollector()
+	c.RecordKeySent("/default/a", "key-1")
+	c.RecordKeySent("/default/a", "key-0")
+	c.RecordKeySent("/default/a", "key-0")
+	c.RecordKeySent("/default/b", "key-0")
+
+	snap := c.Snapshot()
+	if len(snap.KeySends) != 3 {
+		t.Fatalf("key sends size got %d want 3", len(snap.KeySends))
+	}
+	// topics sorted, busiest key first
+	first := snap.KeySends[0]
+	if first.Topic != "/default/a" || first.Key != "key-0" || first.Sent != 2 {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+	if last := snap.KeySends[2]; last.Topic != "/default/b" || last.Sent != 1 {
+		t.Fatalf("unexpected last entry: %+v", last)
+	}
+}
@@ -0,0 +1,126 @@
+package metrics
+
+type ledgerKey struct {
+	Topic    string
+	Producer string
+}
+
+// sendLedger is the producer-side record of one producer's sends on one topic.
+// Sequences are assigned in increasing order, so failures are kept as coalesced ranges.
+type sendLedger struct {
+	Lowest       uint64 // first sequence attempted
+	HighestAcked uint64
+	Acked        uint64
+	failed       []seqRange
+}
+
+// RecordSendAck records that the broker acknowledged seq from producer on topic.
+func (c *Collector) RecordSendAck(topic, producer string, seq uint64) {
+	c.mu.Lock()
+	l := c.ledgerEntry(topic, producer, seq)
+	l.Acked++
+	if seq > l.HighestAcked {
+		l.HighestAcked = seq
+	}
+	c.mu.Unlock()
+}
+
+// RecordSendFailed records that sending seq from producer on topic failed.
+func (c *Collector) RecordSendFailed(topic, producer string, seq uint64) {
+	c.mu.Lock()
+	l := c.ledgerEntry(topic, producer, seq)
+	if n := len(l.failed); n > 0 && l.failed[n-1].Hi+1 == seq {
+		l.failed[n-1].Hi = seq
+	} else {
+		l.failed = append(l.failed, seqRange{Lo: seq, Hi: seq})
+	}
+	c.mu.Unlock()
+}
+
+// Reconcile switches loss accounting from gaps between observed sequences to the
+// producer-side ledger. Call it once sending has stopped and consumers have drained,
+// so that subsequent snapshots count missing heads, tails and producers as loss.
+func (c *Collector) Reconcile() {
+	c.mu.Lock()
+	c.reconciled = true
+	c.mu.Unlock()
+}
+
+// ledgerEntry returns the ledger for topic+producer; caller must hold c.mu.
+func (c *Collector) ledgerEntry(topic, producer string, seq uint64) *sendLedger {
+	k := ledgerKey{Topic: topic, Producer: producer}
+	l, ok := c.ledger[k]
+	if !ok {
+		l = &sendLedger{Lowest: seq}
+		c.ledger[k] = l
+	}
+	if seq < l.Lowest {
+		l.Lowest = seq
+	}
+	return l
+}
+
+// expected returns the sequences every subscription should have received:
+// [Lowest, HighestAcked] minus failed sends, along with the failed count in that range.
+func (l *sendLedger) expected() (expected, failed uint64) {
+	if l.HighestAcked < l.Lowest {
+		return 0, 0
+	}
+	for _, r := range l.failed {
+		if lo, hi := r.Lo, min(r.Hi, l.HighestAcked); lo <= hi {
+			failed += hi - lo + 1
+		}
+	}
+	return l.HighestAcked - l.Lowest + 1 - failed, failed
+}
+
+// reconcile fills the ledger-based fields of entry from the tracker t (nil if nothing arrived).
+func (l *sendLedger) reconcile(entry *IntegrityEntry, t *seqTracker) {
+	expected, failed := l.expected()
+	entry.Reconciled = true
+	entry.Expected = expected
+	entry.FailedSends = failed
+	var seen uint64
+	if t != nil && l.HighestAcked >= l.Lowest {
+		seen = t.seenIn(l.Lowest, l.HighestAcked)
+		for _, r := range l.failed {
+			// a send reported as failed may still have been delivered; it is not expected either way
+			if hi := min(r.Hi, l.HighestAcked); r.Lo <= hi {
+				seen -= t.seenIn(r.Lo, hi)
+			}
+		}
+	}
+	entry.Loss = 0
+	if expected > seen {
+		entry.Loss = expected - seen
+	}
+}
+
+// unseenProducersLocked returns entries for registered subscriptions that never received
+// anything from a producer present in the ledger; caller must hold c.mu.
+func (c *Collector) unseenProducersLocked() []IntegrityEntry {
+	var out []IntegrityEntry
+	for sk, spec := range c.subscriptions {
+		for lk, l := range c.ledger {
+			if lk.Topic != sk.Topic {
+				continue
+			}
+			if _, ok := c.trackers[trackerKey{Topic: sk.Topic, Subscription: sk.Subscription, Producer: lk.Producer}]; ok {
+				continue
+			}
+			entry := IntegrityEntry{
+				Topic:              sk.Topic,
+				Subscription:       sk.Subscription,
+				Producer:           lk.Producer,
+				OrderingExpected:   spec.Ordered,
+				RedeliveryExpected: spec.Reliable,
+				AcksWithheld:       spec.AcksWithheld,
+			}
+			l.reconcile(&entry, nil)
+			if entry.Expected > 0 {
+				out = append(out, entry)
+			}
+		}
+	}
+	return out
+}
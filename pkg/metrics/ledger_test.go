@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestReconcile_CountsHeadTailAndMissingProducers(t *testing.T) {
+	c := NewCollector()
+	topic, sub := "/default/test", "sub"
+	c.RegisterSubscription(SubscriptionSpec{Topic: topic, Subscription: sub})
+
+	// producer-A: sent 1..10, send 4 failed; the subscription saw only 3..8 (head 1..2 and tail 9..10 lost)
+	for s := uint64(1); s <= 10; s++ {
+		if s == 4 {
+			c.RecordSendFailed(topic, "producer-A", s)
+			continue
+		}
+		c.RecordSendAck(topic, "producer-A", s)
+	}
+	for _, s := range []uint64{3, 5, 6, 7, 8} {
+		c.RecordSeq(topic, sub, "producer-A", s)
+	}
+	// producer-B: sent 1..5, nothing ever arrived
+	for s := uint64(1); s <= 5; s++ {
+		c.RecordSendAck(topic, "producer-B", s)
+	}
+
+	// before reconciliation only the observed gap counts (4 looks lost)
+	if got := c.Snapshot().EstimatedLoss; got != 1 {
+		t.Fatalf("gap-based loss got %d want 1", got)
+	}
+
+	c.Reconcile()
+	snap := c.Snapshot()
+	if len(snap.IntegrityBreakdown) != 2 {
+		t.Fatalf("breakdown size got %d want 2", len(snap.IntegrityBreakdown))
+	}
+	for _, e := range snap.IntegrityBreakdown {
+		if !e.Reconciled {
+			t.Fatalf("entry not reconciled: %+v", e)
+		}
+		switch e.Producer {
+		case "producer-A":
+			// expected 9 (10 minus the failed send), seen 5
+			if e.Expected != 9 || e.FailedSends != 1 || e.Loss != 4 {
+				t.Fatalf("producer-A got expected=%d failed=%d loss=%d", e.Expected, e.FailedSends, e.Loss)
+			}
+		case "producer-B":
+			if e.Expected != 5 || e.Loss != 5 || e.UniqueSeen != 0 {
+				t.Fatalf("producer-B got %+v", e)
+			}
+		}
+	}
+	if snap.EstimatedLoss != 9 {
+		t.Fatalf("EstimatedLoss got %d want 9", snap.EstimatedLoss)
+	}
+}
+
+func TestReconcile_IgnoresUnackedInFlight(t *testing.T) {
+	c := NewCollector()
+	topic, sub := "/default/test", "sub"
+	c.RegisterSubscription(SubscriptionSpec{Topic: topic, Subscription: sub})
+	for s := uint64(1); s <= 5; s++ {
+		c.RecordSendAck(topic, "p", s)
+	}
+	// 6 and 7 arrived before their acks were recorded; a failed send that was delivered anyway
+	c.RecordSendFailed(topic, "p", 3)
+	for s := uint64(1); s <= 7; s++ {
+		c.RecordSeq(topic, sub, "p", s)
+	}
+	c.Reconcile()
+	e := c.Snapshot().IntegrityBreakdown[0]
+	if e.Expected != 4 || e.Loss != 0 {
+		t.Fatalf("got expected=%d loss=%d want 4/0", e.Expected, e.Loss)
+	}
+}
@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+)
+
+type partitionKey struct {
+	Topic     string
+	Partition string
+}
+
+type partitionTracker struct {
+	Received  uint64
+	latencies reservoir // milliseconds
+}
+
+// PartitionStats holds receive counts and latency for one partition of a partitioned topic
+type PartitionStats struct {
+	Topic     string       `json:"topic"`
+	Partition string       `json:"partition"`
+	Received  uint64       `json:"received"`
+	LatencyMs Distribution `json:"latency_ms"`
+}
+
+// PartitionSkew compares the busiest and quietest partition of a topic.
+// Configured partitions count even if they received nothing; when one did (Idle > 0)
+// the ratio is unbounded and MaxMinRatio is left at 0.
+type PartitionSkew struct {
+	Topic       string  `json:"topic"`
+	Partitions  int     `json:"partitions"` // configured and observed partitions
+	Idle        int     `json:"idle"`       // partitions that received no message
+	MaxReceived uint64  `json:"max_received"`
+	MinReceived uint64  `json:"min_received"`
+	MaxMinRatio float64 `json:"max_min_ratio"`
+}
+
+// Unbounded reports whether some partition received nothing while another received messages.
+func (sk PartitionSkew) Unbounded() bool {
+	return sk.MinReceived == 0 && sk.MaxReceived > 0
+}
+
+// RegisterPartitions declares the partitions of a partitioned topic, named as the broker names
+// them (<topic>-part-<i>), so partitions that never receive a message still show up in the skew.
+func (c *Collector) RegisterPartitions(topic string, partitions int) {
+	c.mu.Lock()
+	for i := 0; i < partitions; i++ {
+		c.partition(topic, fmt.Sprintf("%s-part-%d", topic, i))
+	}
+	c.mu.Unlock()
+}
+
+// RecordPartitionReceive counts a message received from the given partition of topic.
+func (c *Collector) RecordPartitionReceive(topic, partition string) {
+	c.mu.Lock()
+	c.partition(topic, partition).Received++
+	c.mu.Unlock()
+}
+
+// RecordPartitionLatency adds an end-to-end latency sample (ms) for the given partition of topic.
+func (c *Collector) RecordPartitionLatency(topic, partition string, ms float64) {
+	c.mu.Lock()
+	c.partition(topic, partition).latencies.add(ms)
+	c.mu.Unlock()
+}
+
+// partition returns the tracker for topic+partition; caller must hold c.mu.
+func (c *Collector) partition(topic, partition string) *partitionTracker {
+	k := partitionKey{Topic: topic, Partition: partition}
+	pt, ok := c.partitions[k]
+	if !ok {
+		pt = &partitionTracker{}
+		c.partitions[k] = pt
+	}
+	return pt
+}
+
+// partitionStatsLocked builds per-partition stats sorted by topic then partition; caller must hold c.mu.
+func (c *Collector) partitionStatsLocked() []PartitionStats {
+	var out []PartitionStats
+	for k, pt := range c.partitions {
+		out = append(out, PartitionStats{
+			Topic:     k.Topic,
+			Partition: k.Partition,
+			Received:  pt.Received,
+			LatencyMs: pt.latencies.summary(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Topic != out[j].Topic {
+			return out[i].Topic < out[j].Topic
+		}
+		return out[i].Partition < out[j].Partition
+	})
+	return out
+}
+
+// partitionSkew computes max/min receive ratios per topic from stats sorted by topic.
+func partitionSkew(stats []PartitionStats) []PartitionSkew {
+	var out []PartitionSkew
+	for i := 0; i < len(stats); {
+		sk := PartitionSkew{Topic: stats[i].Topic, MinReceived: stats[i].Received}
+		j := i
+		for j < len(stats) && stats[j].Topic == sk.Topic {
+			r := stats[j].Received
+			if r > sk.MaxReceived {
+				sk.MaxReceived = r
+			}
+			if r < sk.MinReceived {
+				sk.MinReceived = r
+			}
+			if r == 0 {
+				sk.Idle++
+			}
+			j++
+		}
+		sk.Partitions = j - i
+		if sk.MinReceived > 0 {
+			sk.MaxMinRatio = float64(sk.MaxReceived) / float64(sk.MinReceived)
+		}
+		out = append(out, sk)
+		i = j
+	}
+	return out
+}
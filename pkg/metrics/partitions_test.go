@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestPartitionStatsAndSkew(t *testing.T) {
+	c := NewCollector()
+	topic := "/default/orders"
+	for i := 0; i < 6; i++ {
+		c.RecordPartitionReceive(topic, topic+"-part-0")
+		c.RecordPartitionLatency(topic, topic+"-part-0", float64(i+1))
+	}
+	for i := 0; i < 2; i++ {
+		c.RecordPartitionReceive(topic, topic+"-part-1")
+		c.RecordPartitionLatency(topic, topic+"-part-1", 50)
+	}
+	c.RecordPartitionReceive("/default/other", "/default/other-part-0")
+
+	snap := c.Snapshot()
+	if len(snap.Partitions) != 3 {
+		t.Fatalf("partitions size got %d want 3", len(snap.Partitions))
+	}
+	p0 := snap.Partitions[0]
+	if p0.Topic != topic || p0.Partition != topic+"-part-0" || p0.Received != 6 {
+		t.Fatalf("unexpected first partition: %+v", p0)
+	}
+	if p0.LatencyMs.Count != 6 || p0.LatencyMs.Max != 6 {
+		t.Fatalf("unexpected latency for part-0: %+v", p0.LatencyMs)
+	}
+	if p1 := snap.Partitions[1]; p1.LatencyMs.P50 != 50 {
+		t.Fatalf("part-1 p50 got %v want 50", p1.LatencyMs.P50)
+	}
+
+	if len(snap.PartitionSkew) != 2 {
+		t.Fatalf("skew size got %d want 2", len(snap.PartitionSkew))
+	}
+	// topics sorted: /default/orders before /default/other
+	sk := snap.PartitionSkew[0]
+	if sk.Topic != topic || sk.Partitions != 2 || sk.MaxReceived != 6 || sk.MinReceived != 2 || sk.MaxMinRatio != 3 {
+		t.Fatalf("unexpected skew: %+v", sk)
+	}
+}
+
+func TestPartitionSkewCountsIdlePartitions(t *testing.T) {
+	c := NewCollector()
+	topic := "/default/orders"
+	c.RegisterPartitions(topic, 3)
+	for i := 0; i < 4; i++ {
+		c.RecordPartitionReceive(topic, topic+"-part-0")
+	}
+	c.RecordPartitionReceive(topic, topic+"-part-1")
+
+	snap := c.Snapshot()
+	if len(snap.Partitions) != 3 || snap.Partitions[2].Received != 0 {
+		t.Fatalf("configured partitions must be listed: %+v", snap.Partitions)
+	}
+	sk := snap.PartitionSkew[0]
+	if sk.Partitions != 3 || sk.Idle != 1 || sk.MinReceived != 0 || !sk.Unbounded() || sk.MaxMinRatio != 0 {
+		t.Fatalf("unexpected skew: %+v", sk)
+	}
+}
@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// producerCreateTracker holds producer.Create and Close timings for one producer group.
+// Churn recreates producers for the whole run, so timings are sampled in bounded memory.
+type producerCreateTracker struct {
+	ok          reservoir // milliseconds, successful creates
+	failed      reservoir // milliseconds, failed creates
+	closed      reservoir // milliseconds, successful closes
+	closeFailed int
+}
+
+// ProducerCreateStats summarizes producer creation and close cost for a producer group.
+// Groups with churn recreate producers, so Creates and Closes grow with the recreation rate.
+type ProducerCreateStats struct {
+	Group          string       `json:"group"`
+	Creates        int          `json:"creates"`
+	Failures       int          `json:"failures"`
+	CreateMs       Distribution `json:"create_ms"`
+	FailedCreateMs Distribution `json:"failed_create_ms"`
+	Closes         int          `json:"closes"`
+	CloseFailures  int          `json:"close_failures"`
+	CloseMs        Distribution `json:"close_ms"`
+}
+
+// RecordProducerCreate records how long a producer.Create call of group took and whether it failed.
+func (c *Collector) RecordProducerCreate(group string, took time.Duration, err error) {
+	ms := float64(took.Microseconds()) / 1000
+	c.mu.Lock()
+	t := c.producerCreate(group)
+	if err != nil {
+		t.failed.add(ms)
+	} else {
+		t.ok.add(ms)
+	}
+	c.mu.Unlock()
+}
+
+// RecordProducerClose records how long closing a producer of group took and whether it failed.
+func (c *Collector) RecordProducerClose(group string, took time.Duration, err error) {
+	ms := float64(took.Microseconds()) / 1000
+	c.mu.Lock()
+	t := c.producerCreate(group)
+	if err != nil {
+		t.closeFailed++
+	} else {
+		t.closed.add(ms)
+	}
+	c.mu.Unlock()
+}
+
+// producerCreate returns the tracker for group; caller must hold c.mu.
+func (c *Collector) producerCreate(group string) *producerCreateTracker {
+	t, ok := c.producerCreates[group]
+	if !ok {
+		t = &producerCreateTracker{}
+		c.producerCreates[group] = t
+	}
+	return t
+}
+
+// producerCreateStatsLocked builds per-group creation stats; caller must hold c.mu.
+func (c *Collector) producerCreateStatsLocked() []ProducerCreateStats {
+	var out []ProducerCreateStats
+	for group, t := range c.producerCreates {
+		out = append(out, ProducerCreateStats{
+			Group:          group,
+			Creates:        t.ok.n,
+			Failures:       t.failed.n,
+			CreateMs:       t.ok.summary(),
+			FailedCreateMs: t.failed.summary(),
+			Closes:         t.closed.n,
+			CloseFailures:  t.closeFailed,
+			CloseMs:        t.closed.summary(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Group < out[j].Group })
+	return out
+}
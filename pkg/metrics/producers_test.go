@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProducerCreateStats(t *testing.T) {
+	c := NewCollector()
+	c.RecordProducerCreate("churny", 10*time.Millisecond, nil)
+	c.RecordProducerCreate("churny", 30*time.Millisecond, nil)
+	c.RecordProducerCreate("churny", 500*time.Millisecond, errors.New("deadline exceeded"))
+	c.RecordProducerCreate("steady", 5*time.Millisecond, nil)
+
+	stats := c.Snapshot().ProducerCreates
+	if len(stats) != 2 || stats[0].Group != "churny" || stats[1].Group != "steady" {
+		t.Fatalf("unexpected groups: %+v", stats)
+	}
+	s := stats[0]
+	if s.Creates != 2 || s.Failures != 1 {
+		t.Fatalf("creates/failures got %d/%d want 2/1", s.Creates, s.Failures)
+	}
+	if s.CreateMs.Count != 2 || s.CreateMs.Max != 30 {
+		t.Fatalf("create latency got %+v", s.CreateMs)
+	}
+	// failures are kept out of the success histogram
+	if s.FailedCreateMs.Count != 1 || s.FailedCreateMs.Max != 500 {
+		t.Fatalf("failed create latency got %+v", s.FailedCreateMs)
+	}
+}
+
+func TestProducerCloseStats(t *testing.T) {
+	c := NewCollector()
+	c.RecordProducerCreate("churny", 10*time.Millisecond, nil)
+	c.RecordProducerClose("churny", 4*time.Millisecond, nil)
+	c.RecordProducerClose("churny", 8*time.Millisecond, nil)
+	c.RecordProducerClose("churny", time.Second, errors.New("closed"))
+
+	s := c.Snapshot().ProducerCreates[0]
+	if s.Closes != 2 || s.CloseFailures != 1 || s.CloseMs.Max != 8 {
+		t.Fatalf("close stats got closes=%d failures=%d %+v", s.Closes, s.CloseFailures, s.CloseMs)
+	}
+	if s.Creates != 1 {
+		t.Fatalf("creates got %d want 1", s.Creates)
+	}
+}
+
+func TestProducerCreateStatsBounded(t *testing.T) {
+	c := NewCollector()
+	for i := 1; i <= 3*reservoirSize; i++ {
+		c.RecordProducerCreate("churny", time.Duration(i)*time.Millisecond, nil)
+		c.RecordProducerClose("churny", time.Millisecond, nil)
+	}
+	tr := c.producerCreates["churny"]
+	if len(tr.ok.samples) > reservoirSize || len(tr.closed.samples) > reservoirSize {
+		t.Fatalf("samples grew to %d creates and %d closes", len(tr.ok.samples), len(tr.closed.samples))
+	}
+	s := c.Snapshot().ProducerCreates[0]
+	if s.Creates != 3*reservoirSize || s.Closes != 3*reservoirSize || s.CreateMs.Max != 3*reservoirSize {
+		t.Fatalf("counts must stay exact: creates=%d closes=%d max=%v", s.Creates, s.Closes, s.CreateMs.Max)
+	}
+}
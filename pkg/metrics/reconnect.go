@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// reconnectTracker holds disconnects and the downtime until each worker came back, for one role+group.
+type reconnectTracker struct {
+	disconnects int
+	downtimes   []float64            // milliseconds, recovered disconnects
+	open        map[string]time.Time // workers currently disconnected, by worker name
+}
+
+// ReconnectStats summarizes lost connections of a producer or consumer group.
+type ReconnectStats struct {
+	Role        string       `json:"role"` // producer|consumer
+	Group       string       `json:"group"`
+	Disconnects int          `json:"disconnects"`
+	Reconnects  int          `json:"reconnects"`
+	Unrecovered int          `json:"unrecovered"` // still disconnected at snapshot time
+	DowntimeMs  Distribution `json:"downtime_ms"`
+	// DowntimeSec sums worker downtime, including workers still disconnected
+	DowntimeSec float64 `json:"downtime_sec"`
+}
+
+// RecordDisconnect marks worker as disconnected from now until RecordReconnect.
+// Repeated calls while it is already disconnected are ignored.
+func (c *Collector) RecordDisconnect(role, group, worker string) {
+	now := time.Now()
+	c.mu.Lock()
+	t := c.reconnectTracker(role, group)
+	if _, ok := t.open[worker]; !ok {
+		t.open[worker] = now
+		t.disconnects++
+	}
+	c.mu.Unlock()
+}
+
+// RecordReconnect marks worker as connected again and records its downtime.
+func (c *Collector) RecordReconnect(role, group, worker string) {
+	now := time.Now()
+	c.mu.Lock()
+	t := c.reconnectTracker(role, group)
+	if since, ok := t.open[worker]; ok {
+		delete(t.open, worker)
+		t.downtimes = append(t.downtimes, float64(now.Sub(since).Microseconds())/1000)
+	}
+	c.mu.Unlock()
+}
+
+// reconnectTracker returns the tracker for role+group; caller must hold c.mu.
+func (c *Collector) reconnectTracker(role, group string) *reconnectTracker {
+	k := setupKey{Role: role, Group: group}
+	t, ok := c.reconnects[k]
+	if !ok {
+		t = &reconnectTracker{open: make(map[string]time.Time)}
+		c.reconnects[k] = t
+	}
+	return t
+}
+
+// reconnectStatsLocked builds per-group reconnect stats, producers first; caller must hold c.mu.
+func (c *Collector) reconnectStatsLocked(now time.Time) []ReconnectStats {
+	var out []ReconnectStats
+	for k, t := range c.reconnects {
+		var total float64
+		for _, d := range t.downtimes {
+			total += d / 1000
+		}
+		for _, since := range t.open {
+			total += now.Sub(since).Seconds()
+		}
+		out = append(out, ReconnectStats{
+			Role:        k.Role,
+			Group:       k.Group,
+			Disconnects: t.disconnects,
+			Reconnects:  len(t.downtimes),
+			Unrecovered: len(t.open),
+			DowntimeMs:  summarize(t.downtimes),
+			DowntimeSec: total,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Role != out[j].Role {
+			return out[i].Role > out[j].Role
+		}
+		return out[i].Group < out[j].Group
+	})
+	return out
+}
@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReconnectDowntime(t *testing.T) {
+	c := NewCollector()
+	c.RecordDisconnect(SetupConsumer, "subs", "subs-0")
+	// a second report for the same outage does not count twice
+	c.RecordDisconnect(SetupConsumer, "subs", "subs-0")
+	time.Sleep(20 * time.Millisecond)
+	c.RecordReconnect(SetupConsumer, "subs", "subs-0")
+	c.RecordDisconnect(SetupConsumer, "subs", "subs-1")
+	// reconnect without a disconnect is ignored
+	c.RecordReconnect(SetupProducer, "pubs", "pubs-0")
+
+	stats := c.Snapshot().Reconnects
+	if len(stats) != 2 || stats[0].Role != SetupProducer || stats[1].Role != SetupConsumer {
+		t.Fatalf("unexpected entries: %+v", stats)
+	}
+	if p := stats[0]; p.Disconnects != 0 || p.Reconnects != 0 {
+		t.Fatalf("unexpected producer entry: %+v", p)
+	}
+	s := stats[1]
+	if s.Disconnects != 2 || s.Reconnects != 1 || s.Unrecovered != 1 {
+		t.Fatalf("unexpected consumer entry: %+v", s)
+	}
+	if s.DowntimeMs.Count != 1 || s.DowntimeMs.Max < 20 {
+		t.Fatalf("downtime got %+v want one sample >= 20ms", s.DowntimeMs)
+	}
+	if s.DowntimeSec < 0.02 {
+		t.Fatalf("total downtime got %v want >= 0.02s", s.DowntimeSec)
+	}
+}
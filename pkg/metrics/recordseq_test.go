@@ -60,3 +60,36 @@ func TestRecordSeq_IsolatedKeysDoNotInterfere(t *testing.T) {
 		t.Fatalf("breakdown size got %d want 2", len(snap.IntegrityBreakdown))
 	}
 }
+
+func TestRecordSeq_OutOfOrder(t *testing.T) {
+	c := NewCollector()
+	topic, sub, prod := "/default/test", "sub", "producer-1"
+	c.RegisterSubscription(SubscriptionSpec{Topic: topic, Subscription: sub, Ordered: true})
+	// 1,2,5,3,4,5 => 3 and 4 arrive after 5 (distances 2 and 1), second 5 is a duplicate not a reorder
+	for _, s := range []uint64{1, 2, 5, 3, 4, 5} {
+		c.RecordSeq(topic, sub, prod, s)
+	}
+	// unordered subscription on the same topic sees one reorder but no violation
+	c.RecordSeq(topic, "shared", prod, 2)
+	c.RecordSeq(topic, "shared", prod, 1)
+
+	snap := c.Snapshot()
+	if snap.OutOfOrder != 3 {
+		t.Fatalf("OutOfOrder got %d want 3", snap.OutOfOrder)
+	}
+	if snap.OrderingViolations != 2 {
+		t.Fatalf("OrderingViolations got %d want 2", snap.OrderingViolations)
+	}
+	for _, e := range snap.IntegrityBreakdown {
+		switch e.Subscription {
+		case sub:
+			if !e.OrderingExpected || e.OutOfOrder != 2 || e.MaxReorderDistance != 2 || e.Duplicates != 1 {
+				t.Fatalf("unexpected ordered entry: %+v", e)
+			}
+		case "shared":
+			if e.OrderingExpected || e.OutOfOrder != 1 || e.OrderingViolations != 0 {
+				t.Fatalf("unexpected shared entry: %+v", e)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package metrics
+
+import (
+	"time"
+)
+
+// recentDeliverySlots is how many recent first deliveries each tracker remembers
+// (direct-mapped by seq) to tell redeliveries from cross-consumer duplicates.
+const recentDeliverySlots = 1024
+
+// maxUnackedTracked caps the deliveries per tracker remembered as unacked until they come back.
+const maxUnackedTracked = 1 << 16
+
+// delivery remembers who first received a sequence and when.
+type delivery struct {
+	Seq      uint64
+	At       int64  // unix nanoseconds
+	Consumer uint16 // index into seqTracker.consumers, plus one (0 means empty slot)
+}
+
+// deliver records seq received by consumer at now, classifying duplicates:
+// a sequence whose ack failed or was skipped coming back to any consumer is a redelivery,
+// as is the same consumer receiving a sequence again; another consumer receiving an acked
+// sequence is a cross-consumer duplicate.
+func (t *seqTracker) deliver(seq uint64, consumer string, now time.Time) {
+	id := t.consumerID(consumer)
+	if t.recent == nil {
+		t.recent = make([]delivery, recentDeliverySlots)
+	}
+	slot := &t.recent[seq%recentDeliverySlots]
+	if !t.record(seq) {
+		*slot = delivery{Seq: seq, At: now.UnixNano(), Consumer: id}
+		return
+	}
+	// unacked deliveries are remembered apart from the slots, so a redelivery after a long
+	// ack timeout is still recognized; the broker may hand it to any consumer
+	if at, ok := t.unacked[seq]; ok {
+		delete(t.unacked, seq)
+		t.redelivered(now.UnixNano() - at)
+		return
+	}
+	switch {
+	case slot.Consumer == 0 || slot.Seq != seq:
+		t.UnclassifiedDuplicates++
+	case slot.Consumer == id:
+		t.redelivered(now.UnixNano() - slot.At)
+	default:
+		t.CrossDuplicates++
+	}
+}
+
+// unack records that the delivery of seq at or before now was not acked, so the broker
+// is expected to deliver it again.
+func (t *seqTracker) unack(seq uint64, now time.Time) {
+	if _, ok := t.unacked[seq]; ok {
+		return
+	}
+	if t.unacked == nil {
+		t.unacked = make(map[uint64]int64)
+	}
+	if len(t.unacked) >= maxUnackedTracked {
+		return
+	}
+	at := now.UnixNano()
+	// time the redelivery from the delivery itself when it is still remembered
+	if slot := t.recent[seq%recentDeliverySlots]; slot.Consumer != 0 && slot.Seq == seq {
+		at = slot.At
+	}
+	t.unacked[seq] = at
+}
+
+// redelivered counts a redelivery that came tookNs after the first delivery.
+func (t *seqTracker) redelivered(tookNs int64) {
+	t.Redeliveries++
+	t.redeliveryDelays.add(float64(tookNs) / 1e6)
+}
+
+// consumerID maps a consumer name to its 1-based index within the tracker.
+func (t *seqTracker) consumerID(name string) uint16 {
+	for i, c := range t.consumers {
+		if c == name {
+			return uint16(i + 1)
+		}
+	}
+	t.consumers = append(t.consumers, name)
+	return uint16(len(t.consumers))
+}
@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestRecordDelivery_ClassifiesDuplicates(t *testing.T) {
+	c := NewCollector()
+	topic, sub, prod := "/default/reliable", "sub", "producer-1"
+	c.RegisterSubscription(SubscriptionSpec{Topic: topic, Subscription: sub, Reliable: true})
+
+	c.RecordDelivery(topic, sub, prod, "c-0", 1)
+	c.RecordDelivery(topic, sub, prod, "c-1", 2)
+	c.RecordDelivery(topic, sub, prod, "c-0", 1) // redelivered to the same consumer
+	c.RecordDelivery(topic, sub, prod, "c-0", 2) // delivered to a second consumer
+	c.RecordDelivery(topic, sub, prod, "c-0", 3)
+	// 3 is evicted from the recent-delivery memory by a sequence mapping to the same slot
+	c.RecordDelivery(topic, sub, prod, "c-0", 3+recentDeliverySlots)
+	c.RecordDelivery(topic, sub, prod, "c-0", 3)
+
+	snap := c.Snapshot()
+	if snap.Duplicates != 3 {
+		t.Fatalf("Duplicates got %d want 3", snap.Duplicates)
+	}
+	e := snap.IntegrityBreakdown[0]
+	if e.Redeliveries != 1 || e.CrossConsumerDuplicates != 1 || e.UnclassifiedDuplicates != 1 {
+		t.Fatalf("classification got redeliv=%d cross=%d unclassified=%d want 1/1/1",
+			e.Redeliveries, e.CrossConsumerDuplicates, e.UnclassifiedDuplicates)
+	}
+	if e.RedeliveryDelayMs.Count != 1 || e.RedeliveryDelayMs.Max < 0 {
+		t.Fatalf("unexpected redelivery delay distribution: %+v", e.RedeliveryDelayMs)
+	}
+	// the redelivery is expected on a reliable topic; the other two duplicates are not
+	if !e.RedeliveryExpected || e.UnexpectedDuplicates() != 2 || e.InSLA() {
+		t.Fatalf("unexpected SLA evaluation: expected=%v unexpected_dups=%d in_sla=%v",
+			e.RedeliveryExpected, e.UnexpectedDuplicates(), e.InSLA())
+	}
+}
+
+func TestUnexpectedDuplicates_AcksWithheld(t *testing.T) {
+	c := NewCollector()
+	topic, sub := "/default/reliable", "sub"
+	c.RegisterSubscription(SubscriptionSpec{Topic: topic, Subscription: sub, Reliable: true, AcksWithheld: true})
+	// a second group on the same subscription acks normally; the withheld flag must stick
+	c.RegisterSubscription(SubscriptionSpec{Topic: topic, Subscription: sub, Reliable: true})
+	c.RecordDelivery(topic, sub, "p", "c-0", 1)
+	c.RecordDelivery(topic, sub, "p", "c-1", 1) // unacked message came back to another consumer
+
+	e := c.Snapshot().IntegrityBreakdown[0]
+	if !e.AcksWithheld || e.CrossConsumerDuplicates != 1 || e.UnexpectedDuplicates() != 0 || !e.InSLA() {
+		t.Fatalf("withheld acks should explain the duplicate: %+v", e)
+	}
+}
+
+func TestRecordUnacked_RedeliveryToAnotherConsumer(t *testing.T) {
+	c := NewCollector()
+	topic, sub, prod := "/default/reliable", "shared-sub", "producer-1"
+	c.RegisterSubscription(SubscriptionSpec{Topic: topic, Subscription: sub, Reliable: true})
+
+	c.RecordDelivery(topic, sub, prod, "c-0", 1)
+	c.RecordDelivery(topic, sub, prod, "c-0", 2)
+	c.RecordUnacked(topic, sub, prod, 1) // ack failed: the broker hands it to another consumer
+	// enough later deliveries to evict 1 from the recent-delivery slots
+	for seq := uint64(3); seq <= 3+recentDeliverySlots; seq++ {
+		c.RecordDelivery(topic, sub, prod, "c-0", seq)
+	}
+	c.RecordDelivery(topic, sub, prod, "c-1", 1)
+	c.RecordDelivery(topic, sub, prod, "c-1", 3+recentDeliverySlots) // acked: still a cross-consumer duplicate
+
+	e := c.Snapshot().IntegrityBreakdown[0]
+	if e.Redeliveries != 1 || e.CrossConsumerDuplicates != 1 || e.UnclassifiedDuplicates != 0 {
+		t.Fatalf("classification got redeliv=%d cross=%d unclassified=%d want 1/1/0",
+			e.Redeliveries, e.CrossConsumerDuplicates, e.UnclassifiedDuplicates)
+	}
+	if e.RedeliveryDelayMs.Count != 1 {
+		t.Fatalf("unexpected redelivery delay distribution: %+v", e.RedeliveryDelayMs)
+	}
+}
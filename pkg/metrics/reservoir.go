@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"math/rand"
+)
+
+// reservoirSize is how many samples a reservoir keeps; percentiles are estimated from them.
+const reservoirSize = 4096
+
+// reservoir keeps a uniform random sample of at most reservoirSize values, plus the exact
+// count and maximum, so long runs summarize in bounded memory.
+type reservoir struct {
+	samples []float64
+	n       int
+	max     float64
+}
+
+// add offers v to the sample.
+func (r *reservoir) add(v float64) {
+	r.n++
+	if r.n == 1 || v > r.max {
+		r.max = v
+	}
+	if len(r.samples) < reservoirSize {
+		r.samples = append(r.samples, v)
+		return
+	}
+	// algorithm R: the i-th value replaces a random sample with probability size/i
+	if i := rand.Int63n(int64(r.n)); i < reservoirSize {
+		r.samples[i] = v
+	}
+}
+
+// summary returns the distribution of the sample with the exact count and maximum.
+func (r *reservoir) summary() Distribution {
+	d := summarize(r.samples)
+	if r.n > 0 {
+		d.Count, d.Max = r.n, r.max
+	}
+	return d
+}
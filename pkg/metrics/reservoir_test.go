@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"testing"
+)
+
+func TestReservoirBoundsSamples(t *testing.T) {
+	var r reservoir
+	for i := 1; i <= 10*reservoirSize; i++ {
+		r.add(float64(i))
+	}
+	if len(r.samples) != reservoirSize {
+		t.Fatalf("kept %d samples want %d", len(r.samples), reservoirSize)
+	}
+	d := r.summary()
+	if d.Count != 10*reservoirSize || d.Max != 10*reservoirSize {
+		t.Fatalf("count and max must be exact: %+v", d)
+	}
+	// uniform sample of 1..N: the median lands near N/2
+	if mid := float64(5 * reservoirSize); d.P50 < 0.9*mid || d.P50 > 1.1*mid {
+		t.Fatalf("p50 %v too far from %v", d.P50, mid)
+	}
+}
@@ -0,0 +1,275 @@
+package metrics
+
+import (
+	"sort"
+)
+
+// seqWindowBits is the number of sequences tracked individually above the low-water mark.
+// Reordering deeper than this settles the skipped sequences as missing; they are still
+// accounted exactly (as late arrivals) if they show up afterwards.
+const seqWindowBits = 1 << 16
+
+// maxMissingRanges caps the gaps a tracker lists individually. Beyond it the oldest gap is
+// given up at the horizon: its sequences stay counted as loss for good, and anything arriving
+// at or below the horizon is counted as unverifiable, since it may be a duplicate or a late
+// arrival from a given-up gap.
+const maxMissingRanges = 1 << 14
+
+// seqTracker tracks the sequence numbers of one topic+subscription+producer key in bounded memory.
+//
+// Every sequence in [Min, low] is settled: it has been seen unless it is listed in missing.
+// Sequences in (low, low+seqWindowBits] live in a ring bitmap indexed by seq % seqWindowBits.
+// The low-water mark advances as soon as the window is contiguous, so with little loss the
+// memory used is the fixed window plus one range per gap, independent of the run length.
+// With heavy loss at most maxMissingRanges gaps are kept; below the horizon only totals are.
+type seqTracker struct {
+	Min        uint64
+	Max        uint64
+	Unique     uint64
+	Duplicates uint64
+	// Late counts arrivals of sequences that had already been settled as missing
+	Late uint64
+	// ordering: arrivals below the highest sequence seen so far
+	OutOfOrder uint64
+	MaxReorder uint64
+
+	low     uint64
+	window  []uint64   // ring bitmap of seen sequences above low
+	missing []seqRange // sorted, non-overlapping, non-adjacent gaps at or below low
+
+	// every sequence in [Min, horizon] is settled for good once capped; only totals are kept
+	maxGaps   int // maxMissingRanges; tests lower or lift it
+	capped    bool
+	horizon   uint64
+	seenBelow uint64 // distinct sequences seen in [Min, horizon]
+	Abandoned uint64 // sequences of gaps given up at the horizon
+	// Unverifiable counts arrivals at or below the horizon, neither duplicates nor seen for sure
+	Unverifiable uint64
+
+	// duplicate classification, see redelivery.go
+	Redeliveries           uint64 // same consumer received the sequence again
+	CrossDuplicates        uint64 // a different consumer received the sequence again
+	UnclassifiedDuplicates uint64 // first delivery no longer remembered
+	redeliveryDelays       reservoir
+	recent                 []delivery
+	unacked                map[uint64]int64 // seq -> unix nanoseconds of the unacked delivery
+	consumers              []string
+}
+
+type seqRange struct {
+	Lo, Hi uint64 // inclusive
+}
+
+func newSeqTracker() *seqTracker {
+	return &seqTracker{window: make([]uint64, seqWindowBits/64), maxGaps: maxMissingRanges}
+}
+
+// record accounts one arrival of seq and reports whether it was a duplicate.
+// Unverifiable arrivals are not.
+func (t *seqTracker) record(seq uint64) (dup bool) {
+	if t.Unique == 0 {
+		// first sequence becomes the origin of the window
+		t.Min, t.Max, t.low, t.Unique = seq, seq, seq, 1
+		return false
+	}
+	switch {
+	case seq < t.Min:
+		// Below everything seen so far: the sequences in between are settled as missing,
+		// or given up at once when the horizon already covers them
+		switch {
+		case t.capped:
+			t.Abandoned += t.Min - seq - 1
+			t.seenBelow++
+		case seq+1 < t.Min:
+			t.prependMissing(seqRange{Lo: seq + 1, Hi: t.Min - 1})
+		}
+		t.Min = seq
+	case t.capped && seq <= t.horizon:
+		t.Unverifiable++
+		return false
+	case seq <= t.low:
+		if !t.removeMissing(seq) {
+			t.Duplicates++
+			return true
+		}
+		t.Late++
+	case seq <= t.low+seqWindowBits:
+		if t.bit(seq) {
+			t.Duplicates++
+			return true
+		}
+		t.setBit(seq)
+	default:
+		// Too far ahead for the window: force the mark forward, settling unseen slots as missing
+		t.advanceTo(seq - seqWindowBits)
+		t.setBit(seq)
+	}
+
+	if seq < t.Max {
+		t.OutOfOrder++
+		if d := t.Max - seq; d > t.MaxReorder {
+			t.MaxReorder = d
+		}
+	}
+	if seq > t.Max {
+		t.Max = seq
+	}
+	t.Unique++
+	t.settle()
+	return false
+}
+
+// Loss is the number of sequences in [Min, Max] never seen.
+func (t *seqTracker) Loss() uint64 {
+	if expected := t.Max - t.Min + 1; expected > t.Unique {
+		return expected - t.Unique
+	}
+	return 0
+}
+
+// settle advances the low-water mark over contiguous seen sequences.
+func (t *seqTracker) settle() {
+	for t.low < t.Max && t.bit(t.low+1) {
+		t.clearBit(t.low + 1)
+		t.low++
+	}
+}
+
+// advanceTo moves the low-water mark to newLow, recording unseen sequences as missing.
+func (t *seqTracker) advanceTo(newLow uint64) {
+	end := newLow
+	if end > t.low+seqWindowBits {
+		end = t.low + seqWindowBits
+	}
+	for s := t.low + 1; s <= end; s++ {
+		if t.bit(s) {
+			t.clearBit(s)
+		} else {
+			t.appendMissing(s, s)
+		}
+	}
+	if newLow > end {
+		// beyond the window nothing can have been seen
+		t.appendMissing(end+1, newLow)
+	}
+	t.low = newLow
+}
+
+func (t *seqTracker) bit(seq uint64) bool {
+	i := seq % seqWindowBits
+	return t.window[i/64]&(1<<(i%64)) != 0
+}
+
+func (t *seqTracker) setBit(seq uint64) {
+	i := seq % seqWindowBits
+	t.window[i/64] |= 1 << (i % 64)
+}
+
+func (t *seqTracker) clearBit(seq uint64) {
+	i := seq % seqWindowBits
+	t.window[i/64] &^= 1 << (i % 64)
+}
+
+// appendMissing adds [lo, hi] above every existing gap, merging with the last one when adjacent.
+func (t *seqTracker) appendMissing(lo, hi uint64) {
+	if n := len(t.missing); n > 0 && t.missing[n-1].Hi+1 == lo {
+		t.missing[n-1].Hi = hi
+		return
+	}
+	t.missing = append(t.missing, seqRange{Lo: lo, Hi: hi})
+	t.capMissing()
+}
+
+// prependMissing adds r below every existing gap, merging with the first one when adjacent.
+func (t *seqTracker) prependMissing(r seqRange) {
+	if len(t.missing) > 0 && r.Hi+1 == t.missing[0].Lo {
+		t.missing[0].Lo = r.Lo
+		return
+	}
+	t.missing = append([]seqRange{r}, t.missing...)
+	t.capMissing()
+}
+
+// capMissing gives up the oldest gaps while more than maxGaps are listed,
+// moving the horizon up to the end of each.
+func (t *seqTracker) capMissing() {
+	for len(t.missing) > t.maxGaps {
+		r := t.missing[0]
+		from := t.Min
+		if t.capped {
+			from = t.horizon + 1
+		}
+		// r is the lowest gap, so everything between the previous horizon and r was seen
+		t.seenBelow += r.Lo - from
+		t.Abandoned += r.Hi - r.Lo + 1
+		t.capped, t.horizon = true, r.Hi
+		t.missing = t.missing[1:]
+	}
+}
+
+// footprint approximates the bytes held by the window and the gap list.
+func (t *seqTracker) footprint() int {
+	return len(t.window)*8 + cap(t.missing)*16
+}
+
+// removeMissing deletes seq from the missing gaps, reporting whether it was there.
+func (t *seqTracker) removeMissing(seq uint64) bool {
+	i := sort.Search(len(t.missing), func(i int) bool { return t.missing[i].Hi >= seq })
+	if i == len(t.missing) || t.missing[i].Lo > seq {
+		return false
+	}
+	r := t.missing[i]
+	switch {
+	case r.Lo == seq && r.Hi == seq:
+		t.missing = append(t.missing[:i], t.missing[i+1:]...)
+	case r.Lo == seq:
+		t.missing[i].Lo++
+	case r.Hi == seq:
+		t.missing[i].Hi--
+	default:
+		// split the gap around seq
+		t.missing = append(t.missing, seqRange{})
+		copy(t.missing[i+2:], t.missing[i+1:])
+		t.missing[i] = seqRange{Lo: r.Lo, Hi: seq - 1}
+		t.missing[i+1] = seqRange{Lo: seq + 1, Hi: r.Hi}
+		t.capMissing()
+	}
+	return true
+}
+
+// seenIn counts the distinct sequences in [lo, hi] that have been seen.
+// Below the horizon only the total is known, so a span covering part of it counts
+// nothing seen there.
+func (t *seqTracker) seenIn(lo, hi uint64) uint64 {
+	lo, hi = max(lo, t.Min), min(hi, t.Max)
+	if lo > hi {
+		return 0
+	}
+	var seen uint64
+	// settled part: everything seen except the missing gaps
+	if lo <= t.low {
+		shi := min(hi, t.low)
+		from := lo
+		if t.capped && lo <= t.horizon {
+			if lo == t.Min && shi >= t.horizon {
+				seen = t.seenBelow
+			}
+			from = t.horizon + 1
+		}
+		if from <= shi {
+			seen += shi - from + 1
+			for _, r := range t.missing {
+				if a, b := max(r.Lo, from), min(r.Hi, shi); a <= b {
+					seen -= b - a + 1
+				}
+			}
+		}
+	}
+	// window part: count bits
+	for s := max(lo, t.low+1); s <= hi; s++ {
+		if t.bit(s) {
+			seen++
+		}
+	}
+	return seen
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// naiveTracker is the unbounded reference model the bounded tracker must agree with.
+type naiveTracker struct {
+	min, max   uint64
+	seen       map[uint64]struct{}
+	duplicates uint64
+}
+
+func (n *naiveTracker) record(seq uint64) {
+	if n.seen == nil {
+		n.seen = map[uint64]struct{}{}
+		n.min, n.max = seq, seq
+	}
+	if seq < n.min {
+		n.min = seq
+	}
+	if seq > n.max {
+		n.max = seq
+	}
+	if _, ok := n.seen[seq]; ok {
+		n.duplicates++
+		return
+	}
+	n.seen[seq] = struct{}{}
+}
+
+func TestSeqTracker_MatchesNaiveModel(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	tr := newSeqTracker()
+	tr.maxGaps = math.MaxInt // exact accounting; the cap is covered by TestSeqTracker_GapCap
+	var ref naiveTracker
+	next := uint64(1000)
+	for i := 0; i < 400000; i++ {
+		var seq uint64
+		switch x := r.Intn(1000); {
+		case x < 5: // lost: skip a few
+			next += uint64(r.Intn(5) + 1)
+			seq = next
+		case x < 10: // duplicate of something recent
+			seq = next - uint64(r.Intn(50))
+		case x < 12: // very late arrival, beyond the window
+			seq = next - seqWindowBits - uint64(r.Intn(1000))
+		case x < 13: // far jump ahead
+			next += seqWindowBits * 3
+			seq = next
+		case x < 14: // below the first sequence ever seen
+			seq = uint64(r.Intn(1000))
+		default: // in order with small reorder
+			next++
+			seq = next - uint64(r.Intn(3))
+		}
+		tr.record(seq)
+		ref.record(seq)
+	}
+	if tr.Min != ref.min || tr.Max != ref.max {
+		t.Fatalf("range got [%d..%d] want [%d..%d]", tr.Min, tr.Max, ref.min, ref.max)
+	}
+	if tr.Unique != uint64(len(ref.seen)) {
+		t.Fatalf("unique got %d want %d", tr.Unique, len(ref.seen))
+	}
+	if tr.Duplicates != ref.duplicates {
+		t.Fatalf("duplicates got %d want %d", tr.Duplicates, ref.duplicates)
+	}
+	wantLoss := ref.max - ref.min + 1 - uint64(len(ref.seen))
+	if tr.Loss() != wantLoss {
+		t.Fatalf("loss got %d want %d", tr.Loss(), wantLoss)
+	}
+	if tr.Late == 0 {
+		t.Fatalf("expected some late arrivals")
+	}
+}
+
+func TestSeqTracker_LateArrivalFillsGap(t *testing.T) {
+	tr := newSeqTracker()
+	tr.record(1)
+	tr.record(2)
+	tr.record(seqWindowBits + 10) // forces 3..9 out of the window as missing
+	if tr.Loss() != seqWindowBits+10-2-1 {
+		t.Fatalf("loss before late arrival got %d", tr.Loss())
+	}
+	tr.record(5) // late, fills one hole
+	tr.record(5) // now a real duplicate
+	if tr.Late != 1 || tr.Duplicates != 1 {
+		t.Fatalf("late/dup got %d/%d want 1/1", tr.Late, tr.Duplicates)
+	}
+	if tr.Loss() != seqWindowBits+10-3-1 {
+		t.Fatalf("loss after late arrival got %d", tr.Loss())
+	}
+}
+
+func TestSeqTracker_MemoryStaysFlat(t *testing.T) {
+	tr := newSeqTracker()
+	tr.record(1)
+	// Pairs arrive swapped; every 100k-th pair is lost entirely
+	feed := func(from, to uint64) {
+		for s := from; s < to; s += 2 {
+			if s%100000 == 0 {
+				continue
+			}
+			tr.record(s + 1)
+			tr.record(s)
+		}
+	}
+	feed(2, 1_000_000)
+	base := tr.footprint()
+	words, gaps := len(tr.window), len(tr.missing)
+	feed(1_000_000, 5_000_000)
+	after := tr.footprint()
+
+	if len(tr.window) != words {
+		t.Fatalf("window grew from %d to %d words", words, len(tr.window))
+	}
+	if got := len(tr.missing); got > gaps+60 {
+		t.Fatalf("missing ranges grew from %d to %d", gaps, got)
+	}
+	if after > base+1<<10 {
+		t.Fatalf("tracker grew from %d to %d bytes over 4M sequences", base, after)
+	}
+	if tr.Unique < 4_999_000 || tr.Loss() != 98 {
+		t.Fatalf("unexpected accounting: unique=%d loss=%d", tr.Unique, tr.Loss())
+	}
+}
+
+func TestSeqTracker_GapCap(t *testing.T) {
+	tr := newSeqTracker()
+	tr.maxGaps = 8
+	// every third sequence is lost: one gap each
+	var sent uint64
+	for s := uint64(1); s <= 301; s++ {
+		if s%3 != 0 {
+			tr.record(s)
+			sent++
+		}
+	}
+	tr.advanceTo(tr.Max) // settle the window so the gaps are listed
+	if len(tr.missing) > 8 || !tr.capped {
+		t.Fatalf("gaps not capped: %d listed, capped=%v", len(tr.missing), tr.capped)
+	}
+	if tr.Loss() != 100 || tr.Abandoned+uint64(len(tr.missing)) != 100 {
+		t.Fatalf("loss got %d abandoned=%d listed=%d, want 100 in total", tr.Loss(), tr.Abandoned, len(tr.missing))
+	}
+	if got := tr.seenIn(1, 301); got != sent {
+		t.Fatalf("seenIn got %d want %d", got, sent)
+	}
+	// an arrival below the horizon can't be told apart from a duplicate: the old gap stays
+	// lost and the arrival is unverifiable; a recent gap can still be filled
+	tr.record(3)
+	tr.record(2)
+	tr.record(297)
+	if tr.Duplicates != 0 || tr.Unverifiable != 2 || tr.Late != 1 || tr.Loss() != 99 {
+		t.Fatalf("after arrivals got dup=%d unverifiable=%d late=%d loss=%d want 0/2/1/99", tr.Duplicates, tr.Unverifiable, tr.Late, tr.Loss())
+	}
+}
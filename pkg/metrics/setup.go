@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+)
+
+// Setup roles recorded by the producer and consumer pools.
+const (
+	SetupProducer = "producer"
+	SetupConsumer = "consumer"
+)
+
+type setupKey struct {
+	Role  string
+	Group string
+}
+
+// setupTracker holds the initial connection setup of every worker in one group.
+// Churned consumers subscribe again, so timings are sampled in bounded memory.
+type setupTracker struct {
+	setups       reservoir // milliseconds until created or subscribed
+	attempts     reservoir
+	failures     int
+	firstMessage reservoir // milliseconds from subscribed to first message (consumers only)
+}
+
+// SetupStats summarizes how long the workers of a group took to become ready.
+type SetupStats struct {
+	Role     string `json:"role"` // producer|consumer
+	Group    string `json:"group"`
+	Workers  int    `json:"workers"`
+	Failures int    `json:"failures"`
+	// SetupMs is time to create the producer or to subscribe, including retries
+	SetupMs        Distribution `json:"setup_ms"`
+	Attempts       Distribution `json:"attempts"`
+	FirstMessageMs Distribution `json:"first_message_ms"`
+}
+
+// RecordSetup records a worker's initial producer create or subscribe: the time it took
+// including retries, how many attempts were made and whether it finally failed.
+func (c *Collector) RecordSetup(role, group string, took time.Duration, attempts int, err error) {
+	c.mu.Lock()
+	t := c.setupTracker(role, group)
+	t.attempts.add(float64(attempts))
+	if err != nil {
+		t.failures++
+	} else {
+		t.setups.add(float64(took.Microseconds()) / 1000)
+	}
+	c.mu.Unlock()
+}
+
+// RecordFirstMessage records the time from a consumer worker subscribing to its first message.
+func (c *Collector) RecordFirstMessage(group string, took time.Duration) {
+	c.mu.Lock()
+	t := c.setupTracker(SetupConsumer, group)
+	t.firstMessage.add(float64(took.Microseconds()) / 1000)
+	c.mu.Unlock()
+}
+
+// setupTracker returns the tracker for role+group; caller must hold c.mu.
+func (c *Collector) setupTracker(role, group string) *setupTracker {
+	k := setupKey{Role: role, Group: group}
+	t, ok := c.setup[k]
+	if !ok {
+		t = &setupTracker{}
+		c.setup[k] = t
+	}
+	return t
+}
+
+// setupStatsLocked builds per-group setup stats, producers first; caller must hold c.mu.
+func (c *Collector) setupStatsLocked() []SetupStats {
+	var out []SetupStats
+	for k, t := range c.setup {
+		out = append(out, SetupStats{
+			Role:           k.Role,
+			Group:          k.Group,
+			Workers:        t.attempts.n,
+			Failures:       t.failures,
+			SetupMs:        t.setups.summary(),
+			Attempts:       t.attempts.summary(),
+			FirstMessageMs: t.firstMessage.summary(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Role != out[j].Role {
+			return out[i].Role > out[j].Role
+		}
+		return out[i].Group < out[j].Group
+	})
+	return out
+}
+
+// ReadinessStats records the readiness barrier held before traffic was released.
+type ReadinessStats struct {
+	Expected int     `json:"expected"`
+	Ready    int     `json:"ready"`
+	Failed   int     `json:"failed"`
+	Pending  int     `json:"pending"` // not ready when traffic was released
+	TimedOut bool    `json:"timed_out"`
+	WaitMs   float64 `json:"wait_ms"`
+}
+
+// RecordReadiness stores the outcome of the readiness barrier.
+func (c *Collector) RecordReadiness(r ReadinessStats) {
+	c.mu.Lock()
+	c.readiness = &r
+	c.mu.Unlock()
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSetupStats(t *testing.T) {
+	c := NewCollector()
+	c.RecordSetup(SetupConsumer, "subs", 20*time.Millisecond, 1, nil)
+	c.RecordSetup(SetupConsumer, "subs", 600*time.Millisecond, 4, nil)
+	c.RecordSetup(SetupConsumer, "subs", 3*time.Second, 15, errors.New("topic not found"))
+	c.RecordFirstMessage("subs", 5*time.Millisecond)
+	c.RecordSetup(SetupProducer, "pubs", 10*time.Millisecond, 1, nil)
+
+	stats := c.Snapshot().Setup
+	if len(stats) != 2 || stats[0].Role != SetupProducer || stats[1].Role != SetupConsumer {
+		t.Fatalf("unexpected setup entries: %+v", stats)
+	}
+	s := stats[1]
+	if s.Group != "subs" || s.Workers != 3 || s.Failures != 1 {
+		t.Fatalf("unexpected consumer setup: %+v", s)
+	}
+	// failed setups count towards attempts but not towards setup time
+	if s.SetupMs.Count != 2 || s.SetupMs.Max != 600 {
+		t.Fatalf("setup latency got %+v", s.SetupMs)
+	}
+	if s.Attempts.Count != 3 || s.Attempts.Max != 15 {
+		t.Fatalf("attempts got %+v", s.Attempts)
+	}
+	if s.FirstMessageMs.Count != 1 || s.FirstMessageMs.Max != 5 {
+		t.Fatalf("first message got %+v", s.FirstMessageMs)
+	}
+}
@@ -2,24 +2,46 @@ package metrics
 
 import (
 	"encoding/json"
+	"sort"
 )
 
 // Snapshot is an immutable snapshot of collected metrics used for reporting/export.
 type Snapshot struct {
-	ElapsedSec         float64          `json:"elapsed_sec"`
-	MessagesSent       uint64           `json:"messages_sent"`
-	MessagesReceived   uint64           `json:"messages_received"`
-	Errors             uint64           `json:"errors"`
-	ThroughputSent     float64          `json:"throughput_sent"`
-	ThroughputRecv     float64          `json:"throughput_recv"`
-	LatencyP50Ms       float64          `json:"latency_p50_ms"`
-	LatencyP95Ms       float64          `json:"latency_p95_ms"`
-	LatencyP99Ms       float64          `json:"latency_p99_ms"`
-	LatencyMaxMs       float64          `json:"latency_max_ms"`
-	LatencySamples     int              `json:"latency_samples"`
-	Duplicates         uint64           `json:"duplicates"`
-	EstimatedLoss      uint64           `json:"estimated_loss"`
-	IntegrityBreakdown []IntegrityEntry `json:"integrity_breakdown,omitempty"`
+	// ElapsedSec spans traffic and the cooldown drain; receive rates are measured over it.
+	// TrafficSec ends when producers stop; send rates are measured over it.
+	ElapsedSec         float64               `json:"elapsed_sec"`
+	TrafficSec         float64               `json:"traffic_sec,omitempty"`
+	MessagesSent       uint64                `json:"messages_sent"`
+	MessagesReceived   uint64                `json:"messages_received"`
+	Errors             uint64                `json:"errors"`
+	ErrorCategories    map[string]uint64     `json:"error_categories,omitempty"`
+	ErrorBreakdown     []ErrorCount          `json:"error_breakdown,omitempty"`
+	TopErrors          []ErrorMessage        `json:"top_errors,omitempty"`
+	StaleMessages      uint64                `json:"stale_messages,omitempty"`
+	ThroughputSent     float64               `json:"throughput_sent"`
+	ThroughputRecv     float64               `json:"throughput_recv"`
+	LatencyP50Ms       float64               `json:"latency_p50_ms"`
+	LatencyP95Ms       float64               `json:"latency_p95_ms"`
+	LatencyP99Ms       float64               `json:"latency_p99_ms"`
+	LatencyMaxMs       float64               `json:"latency_max_ms"`
+	LatencySamples     int                   `json:"latency_samples"`
+	Duplicates         uint64                `json:"duplicates"`
+	EstimatedLoss      uint64                `json:"estimated_loss"`
+	OutOfOrder         uint64                `json:"out_of_order"`
+	OrderingViolations uint64                `json:"ordering_violations"`
+	IntegrityBreakdown []IntegrityEntry      `json:"integrity_breakdown,omitempty"`
+	KeySends           []KeySendCount        `json:"key_sends,omitempty"`
+	Partitions         []PartitionStats      `json:"partitions,omitempty"`
+	PartitionSkew      []PartitionSkew       `json:"partition_skew,omitempty"`
+	ConsumerGroups     []ConsumerGroupStats  `json:"consumer_groups,omitempty"`
+	ProducerGroups     []ProducerGroupStats  `json:"producer_groups,omitempty"`
+	Failovers          []FailoverStats       `json:"failovers,omitempty"`
+	ProducerCreates    []ProducerCreateStats `json:"producer_creates,omitempty"`
+	Setup              []SetupStats          `json:"setup,omitempty"`
+	Readiness          *ReadinessStats       `json:"readiness,omitempty"`
+	Reconnects         []ReconnectStats      `json:"reconnects,omitempty"`
+	Intervals          []IntervalSample      `json:"intervals,omitempty"`
+	LatencyCurve       []PercentilePoint     `json:"latency_curve,omitempty"`
 }
 
 // IntegrityEntry holds per-key integrity metrics for export and reporting
@@ -32,6 +54,65 @@ type IntegrityEntry struct {
 	UniqueSeen   uint64 `json:"unique_seen"`
 	Loss         uint64 `json:"loss"`
 	Duplicates   uint64 `json:"duplicates"`
+	// Ledger reconciliation (end of run): Expected is what the producer had acknowledged
+	// minus failed sends, and Loss is then measured against it instead of observed gaps.
+	Reconciled  bool   `json:"reconciled"`
+	Expected    uint64 `json:"expected"`
+	FailedSends uint64 `json:"failed_sends"`
+	// LateArrivals counts sequences that arrived after falling behind the tracking window
+	LateArrivals uint64 `json:"late_arrivals"`
+	// Unverifiable counts arrivals from gaps given up under heavy loss; they can't be
+	// told apart from duplicates, so they count as neither duplicates nor seen
+	Unverifiable uint64 `json:"unverifiable,omitempty"`
+	// Duplicate classification: redeliveries reached the same consumer again, cross-consumer
+	// duplicates reached another consumer; unclassified ones outlived the recent-delivery memory.
+	Redeliveries            uint64       `json:"redeliveries"`
+	CrossConsumerDuplicates uint64       `json:"cross_consumer_duplicates"`
+	UnclassifiedDuplicates  uint64       `json:"unclassified_duplicates"`
+	RedeliveryDelayMs       Distribution `json:"redelivery_delay_ms"`
+	RedeliveryExpected      bool         `json:"redelivery_expected"`
+	// AcksWithheld marks subscriptions whose ack mode leaves messages unacked on purpose
+	AcksWithheld bool `json:"acks_withheld"`
+	// Ordering: arrivals below the highest sequence already seen and how far back they landed.
+	// Violations mirror OutOfOrder only where the config implies ordering.
+	OutOfOrder         uint64 `json:"out_of_order"`
+	MaxReorderDistance uint64 `json:"max_reorder_distance"`
+	OrderingExpected   bool   `json:"ordering_expected"`
+	OrderingViolations uint64 `json:"ordering_violations"`
+}
+
+// UnexpectedDuplicates returns the duplicates not explained by expected redelivery.
+// On reliable topics a redelivery to the same consumer is expected; when acks are also
+// withheld on purpose, unacked messages may come back to any consumer of the subscription.
+func (e IntegrityEntry) UnexpectedDuplicates() uint64 {
+	switch {
+	case e.RedeliveryExpected && e.AcksWithheld:
+		return 0
+	case e.RedeliveryExpected:
+		return e.Duplicates - e.Redeliveries
+	}
+	return e.Duplicates
+}
+
+// InSLA reports whether the key saw no loss, no unexpected duplicates and no ordering violations.
+func (e IntegrityEntry) InSLA() bool {
+	return e.Loss == 0 && e.UnexpectedDuplicates() == 0 && e.OrderingViolations == 0
+}
+
+// KeySendCount holds the number of successful sends per topic and routing key
+type KeySendCount struct {
+	Topic string `json:"topic"`
+	Key   string `json:"key"`
+	Sent  uint64 `json:"sent"`
+}
+
+// Distribution summarizes a set of samples; the unit is given by the field holding it
+type Distribution struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P95   float64 `json:"p95"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
 }
 
 // MarshalSnapshot returns a JSON []byte of the snapshot for export.
@@ -47,6 +128,17 @@ func rate(total uint64, elapsedSec float64) float64 {
 	return float64(total) / elapsedSec
 }
 
+// summarize sorts a copy of samples and returns its Distribution.
+func summarize(samples []float64) Distribution {
+	if len(samples) == 0 {
+		return Distribution{}
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	p50, p95, p99, pmax := percentiles(sorted)
+	return Distribution{Count: len(sorted), P50: p50, P95: p95, P99: p99, Max: pmax}
+}
+
 func percentiles(sorted []float64) (p50, p95, p99, pmax float64) {
 	n := len(sorted)
 	if n == 0 {
@@ -30,10 +30,29 @@ func TestSnapshotJSONKeys(t *testing.T) {
 		"latency_samples",
 		"duplicates",
 		"estimated_loss",
+		"out_of_order",
+		"ordering_violations",
 	}
 	// Optional keys (omitempty)
 	optional := map[string]struct{}{
 		"integrity_breakdown": {},
+		"key_sends":           {},
+		"partitions":          {},
+		"partition_skew":      {},
+		"consumer_groups":     {},
+		"failovers":           {},
+		"producer_creates":    {},
+		"setup":               {},
+		"stale_messages":      {},
+		"readiness":           {},
+		"reconnects":          {},
+		"error_categories":    {},
+		"error_breakdown":     {},
+		"top_errors":          {},
+		"producer_groups":     {},
+		"intervals":           {},
+		"traffic_sec":         {},
+		"latency_curve":       {},
 	}
 	allowed := make(map[string]struct{}, len(required)+len(optional))
 	for _, k := range required {
@@ -13,6 +13,7 @@ import (
 
 	"github.com/danube-messaging/loadtest_danube/pkg/config"
 	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/utils"
 	"github.com/danube-messaging/loadtest_danube/pkg/workload"
 )
 
@@ -29,9 +30,12 @@ func NewPool(serviceURL string, cfg *config.Config, m *metrics.Collector) *Pool
 
 // Start launches producer workers for all producer groups in the config.
 // It returns a function to stop all workers (by canceling the given context).
-func (p *Pool) Start(ctx context.Context, wg *sync.WaitGroup) {
-	client := danube.NewClient().ServiceURL(p.serviceURL).Build()
-	for _, pg := range p.cfg.Producers {
+// Each worker reports to ready once its producer is created (or failed) and
+// sends nothing until ready is released; a nil ready does not gate traffic.
+func (p *Pool) Start(ctx context.Context, wg *sync.WaitGroup, ready *utils.Barrier) {
+	for gi, pg := range p.cfg.Producers {
+		group := groupName(pg, gi)
+		p.metrics.RegisterProducerGroup(metrics.ProducerGroupSpec{Name: group, Workers: pg.Count, RatePerSecond: pg.RatePerSecond})
 		// find topic config by name
 		var topicCfg *config.Topic
 		schemaType := "string"
@@ -45,70 +49,78 @@ func (p *Pool) Start(ctx context.Context, wg *sync.WaitGroup) {
 
 		for i := 0; i < pg.Count; i++ {
 			wg.Add(1)
-			go func(group config.ProducerGroup, workerIdx int, schema string) {
+			go func(pg config.ProducerGroup, workerIdx int, schema string) {
 				defer wg.Done()
-				p.runWorker(ctx, client, group, workerIdx, schema)
+				p.runWorker(ctx, pg, group, workerIdx, schema, ready)
 			}(pg, i, schemaType)
 		}
 	}
 }
 
-func (p *Pool) runWorker(ctx context.Context, _ any, pg config.ProducerGroup, idx int, schema string) {
+func (p *Pool) runWorker(ctx context.Context, pg config.ProducerGroup, baseName string, idx int, schema string, ready *utils.Barrier) {
 	// init producer
-	baseName := pg.Name
-	if baseName == "" {
-		baseName = "producer"
-	}
 	prodName := fmt.Sprintf("%s-%d", baseName, idx)
 
-	// Build a client locally to avoid depending on client type names
+	// one client per worker; producers are recreated on it when churn is configured
 	client := danube.NewClient().ServiceURL(p.serviceURL).Build()
-	builder := client.NewProducer(ctx).
-		WithName(prodName).
-		WithTopic(pg.Topic)
 
-	// Apply topic-level partitions and dispatch strategy if configured
-	// Note: producers operate per topic; partitions are internal to Danube
-	// and controlled via WithPartitions on the producer builder.
-	// Dispatch strategy: reliable vs non_reliable.
-	// We need the topic config; lookup again here.
-	var topicCfg *config.Topic
-	for i := range p.cfg.Topics {
-		if p.cfg.Topics[i].Name == pg.Topic {
-			topicCfg = &p.cfg.Topics[i]
-			break
-		}
-	}
-	if topicCfg != nil {
-		if topicCfg.Partitions > 0 {
-			builder = builder.WithPartitions(int32(topicCfg.Partitions))
+	// optional reconnect: retry the initial create, and recreate the producer after a failed
+	// send, with exponential backoff
+	reconnect := p.cfg.Danube.Reconnect != nil
+	backoff := utils.NewBackoff(p.cfg.Danube.Reconnect)
+
+	// Each producer instance runs on its own context so churn can tear it down
+	prodCtx, cancel := ctx, context.CancelFunc(func() {})
+	defer func() { cancel() }()
+	var producer *danube.Producer
+	defer func() {
+		if producer != nil {
+			p.closeProducer(baseName, producer)
 		}
-		// Apply schema: only JSON requires explicit schema configuration.
-		switch topicCfg.SchemaType {
-		case "json":
-			// Name is arbitrary; provide configured JSON schema
-			builder = builder.WithSchema("json_schema", danube.SchemaType_JSON, topicCfg.JSONSchema)
+	}()
+	setupStart := time.Now()
+	var err error
+	for attempts := 1; ; attempts++ {
+		nextCtx, nextCancel := context.WithCancel(ctx)
+		if producer, err = p.newProducer(nextCtx, client, pg, baseName, prodName); err == nil {
+			prodCtx, cancel = nextCtx, nextCancel
+			p.metrics.RecordSetup(metrics.SetupProducer, baseName, time.Since(setupStart), attempts, nil)
+			break
 		}
-		switch topicCfg.DispatchStrategy {
-		case "reliable":
-			builder = builder.WithDispatchStrategy(danube.NewReliableDispatchStrategy())
-		default:
-			// non_reliable or omitted -> default behavior (do nothing)
+		nextCancel()
+		if !reconnect || !utils.Sleep(ctx, backoff.Next()) {
+			p.metrics.RecordSetup(metrics.SetupProducer, baseName, time.Since(setupStart), attempts, err)
+			ready.Fail()
+			return
 		}
 	}
-
-	producer, err := builder.Build()
-	if err != nil {
-		log.Printf("producer build error: %v", err)
-		p.metrics.IncError(1)
+	backoff.Reset()
+	ready.Ready()
+	if !ready.Wait(ctx) {
 		return
 	}
-	if err := producer.Create(ctx); err != nil {
-		log.Printf("producer create error: %v", err)
-		p.metrics.IncError(1)
+	// idle mode holds the producer open without sending
+	if p.cfg.Execution.Mode == "idle" {
+		<-ctx.Done()
 		return
 	}
 
+	// optional churn: recreate the producer every N messages and/or every T
+	var recreateMsgs uint64
+	var recreateEvery time.Duration
+	if ch := pg.Churn; ch != nil {
+		recreateMsgs = uint64(ch.RecreateEveryMessages)
+		if ch.RecreateEvery != "" {
+			if recreateEvery, err = time.ParseDuration(ch.RecreateEvery); err != nil {
+				log.Printf("producer churn config error: %v", err)
+				p.metrics.RecordError(metrics.ErrConfig, metrics.SetupProducer, baseName, err)
+				return
+			}
+		}
+	}
+	createdAt := time.Now()
+	var sinceCreate uint64
+
 	// rate limiter per worker
 	r := pg.RatePerSecond
 	if r <= 0 {
@@ -119,6 +131,33 @@ func (p *Pool) runWorker(ctx context.Context, _ any, pg config.ProducerGroup, id
 		limiter = rate.NewLimiter(rate.Limit(r), r)
 	}
 
+	// optional routing key generator, one per worker (generators are not goroutine-safe)
+	var keys workload.KeyGenerator
+	if rk := pg.RoutingKey; rk != nil {
+		keys, err = workload.NewKeyGenerator(workload.KeySpec{
+			Distribution: rk.Distribution,
+			Keys:         rk.Keys,
+			ZipfSkew:     rk.ZipfSkew,
+			HotKey:       rk.HotKey,
+			HotPercent:   rk.HotPercent,
+		}, time.Now().UnixNano()+int64(idx))
+		if err != nil {
+			log.Printf("routing key config error: %v", err)
+			p.metrics.RecordError(metrics.ErrConfig, metrics.SetupProducer, baseName, err)
+			return
+		}
+	}
+
+	var recreate bool
+	retryDelay := func() time.Duration {
+		if reconnect {
+			return backoff.Next()
+		}
+		// small backoff to avoid hot loop on error
+		return 50 * time.Millisecond
+	}
+
+	runID := p.cfg.Execution.RunID
 	var seq uint64
 	pspec := workload.PayloadSpec{SchemaType: schema, MessageSize: pg.MessageSize}
 
@@ -126,6 +165,32 @@ func (p *Pool) runWorker(ctx context.Context, _ any, pg config.ProducerGroup, id
 		if ctx.Err() != nil {
 			return
 		}
+		if recreate || (recreateMsgs > 0 && sinceCreate >= recreateMsgs) || (recreateEvery > 0 && time.Since(createdAt) >= recreateEvery) {
+			// close the old producer first so churn does not leak producers on the broker
+			cancel()
+			if producer != nil {
+				p.closeProducer(baseName, producer)
+				producer = nil
+			}
+			nextCtx, nextCancel := context.WithCancel(ctx)
+			next, err := p.newProducer(nextCtx, client, pg, baseName, prodName)
+			if err != nil {
+				nextCancel()
+				// keep retrying; the sequence continues on whichever producer gets created
+				if !utils.Sleep(ctx, retryDelay()) {
+					return
+				}
+				continue
+			}
+			prodCtx, cancel, producer = nextCtx, nextCancel, next
+			createdAt = time.Now()
+			sinceCreate = 0
+			if recreate {
+				recreate = false
+				backoff.Reset()
+				p.metrics.RecordReconnect(metrics.SetupProducer, baseName, prodName)
+			}
+		}
 		if limiter != nil {
 			if err := limiter.Wait(ctx); err != nil {
 				return
@@ -137,13 +202,105 @@ func (p *Pool) runWorker(ctx context.Context, _ any, pg config.ProducerGroup, id
 			"seq":      fmt.Sprintf("%d", seq),
 			"producer": prodName,
 		}
-		if _, err := producer.Send(ctx, payload, attrs); err != nil {
-			p.metrics.IncError(1)
+		if runID != "" {
+			attrs["run"] = runID
+		}
+		// The client has no routing key field, so the key travels as an attribute
+		var key string
+		if keys != nil {
+			key = keys.Next()
+			attrs["key"] = key
+		}
+		sinceCreate++
+		if _, err := producer.Send(prodCtx, payload, attrs); err != nil {
+			if ctx.Err() != nil {
+				// interrupted by the end of traffic: the outcome is unknown, so the ledger records
+				// nothing; the seq lies above every acked one and is not expected either way
+				return
+			}
+			p.metrics.RecordError(metrics.ErrSend, metrics.SetupProducer, baseName, err)
+			p.metrics.RecordSendFailed(pg.Topic, prodName, seq)
 			log.Printf("send error topic=%s worker=%d: %v", pg.Topic, idx, err)
-			// small backoff to avoid hot loop on error
-			time.Sleep(50 * time.Millisecond)
+			// with reconnect the producer is recreated after a backoff; downtime lasts until it is back
+			if reconnect {
+				recreate = true
+				p.metrics.RecordDisconnect(metrics.SetupProducer, baseName, prodName)
+			}
+			if !utils.Sleep(ctx, retryDelay()) {
+				return
+			}
 			continue
 		}
 		p.metrics.IncSent(1)
+		p.metrics.RecordGroupSent(baseName)
+		p.metrics.RecordSendAck(pg.Topic, prodName, seq)
+		if keys != nil {
+			p.metrics.RecordKeySent(pg.Topic, key)
+		}
+	}
+}
+
+// groupName returns the producer group name used for worker names and per-group stats.
+// Unnamed groups are named after their index in producers[] so their stats stay apart.
+func groupName(pg config.ProducerGroup, i int) string {
+	if pg.Name == "" {
+		return fmt.Sprintf("producer-%d", i)
+	}
+	return pg.Name
+}
+
+// closeProducer closes producer and records the close latency under group.
+func (p *Pool) closeProducer(group string, producer *danube.Producer) {
+	start := time.Now()
+	err := producer.Close()
+	p.metrics.RecordProducerClose(group, time.Since(start), err)
+	if err != nil {
+		log.Printf("producer close error: %v", err)
+	}
+}
+
+// newProducer builds and creates a producer for pg named name, recording the Create latency
+// under group. Errors are logged and counted.
+func (p *Pool) newProducer(ctx context.Context, client *danube.DanubeClient, pg config.ProducerGroup, group, name string) (*danube.Producer, error) {
+	builder := client.NewProducer(ctx).
+		WithName(name).
+		WithTopic(pg.Topic)
+
+	// Apply topic-level partitions and dispatch strategy if configured
+	// Note: producers operate per topic; partitions are internal to Danube
+	// and controlled via WithPartitions on the producer builder.
+	// Dispatch strategy: reliable vs non_reliable.
+	if topicCfg := config.FindTopic(p.cfg, pg.Topic); topicCfg != nil {
+		if topicCfg.Partitions > 0 {
+			builder = builder.WithPartitions(int32(topicCfg.Partitions))
+		}
+		// Apply schema: only JSON requires explicit schema configuration.
+		switch topicCfg.SchemaType {
+		case "json":
+			// Name is arbitrary; provide configured JSON schema
+			builder = builder.WithSchema("json_schema", danube.SchemaType_JSON, topicCfg.JSONSchema)
+		}
+		switch topicCfg.DispatchStrategy {
+		case "reliable":
+			builder = builder.WithDispatchStrategy(danube.NewReliableDispatchStrategy())
+		default:
+			// non_reliable or omitted -> default behavior (do nothing)
+		}
+	}
+
+	producer, err := builder.Build()
+	if err != nil {
+		log.Printf("producer build error: %v", err)
+		p.metrics.RecordError(metrics.ErrBuild, metrics.SetupProducer, group, err)
+		return nil, err
+	}
+	start := time.Now()
+	err = producer.Create(ctx)
+	p.metrics.RecordProducerCreate(group, time.Since(start), err)
+	if err != nil {
+		log.Printf("producer create error: %v", err)
+		p.metrics.RecordError(metrics.ErrCreate, metrics.SetupProducer, group, err)
+		return nil, err
 	}
+	return producer, nil
 }
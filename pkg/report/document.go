@@ -0,0 +1,348 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/config"
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
+)
+
+// section is one titled part of the final report: key/value facts, an optional table and notes.
+// The text and Markdown renderers lay out the same sections, so both always show the same data.
+type section struct {
+	Title  string
+	Facts  [][2]string
+	Header []string
+	Rows   [][]string
+	Notes  []string
+}
+
+// savedConfig parses the config saved with a result. Results without one give the zero config,
+// so the configured values read as unknown.
+func savedConfig(r results.Result) (config.Config, error) {
+	var cfg config.Config
+	if err := yaml.Unmarshal([]byte(r.ConfigYAML), &cfg); err != nil {
+		return cfg, fmt.Errorf("parse saved config: %w", err)
+	}
+	return cfg, nil
+}
+
+// buildSections turns a result into report sections, in print order. Empty sections are left out.
+func buildSections(r results.Result) ([]section, error) {
+	snap := r.Snapshot
+	cfg, err := savedConfig(r)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []section{summarySection(r, cfg)}
+	add := func(s section) {
+		if len(s.Facts) > 0 || len(s.Rows) > 0 || len(s.Notes) > 0 {
+			out = append(out, s)
+		}
+	}
+	add(thresholdSection(r.Thresholds))
+	add(errorCategorySection(snap))
+	add(errorMessageSection(snap.TopErrors))
+	add(worstKeySection(snap.IntegrityBreakdown))
+	add(orderingSection(snap.IntegrityBreakdown))
+	add(setupSection(snap.Setup))
+	add(reconnectSection(snap.Reconnects))
+	add(producerCreateSection(snap.ProducerCreates))
+	add(producerGroupSection(snap.ProducerGroups))
+	add(consumerGroupSection(snap.ConsumerGroups))
+	add(failoverSection(snap.Failovers))
+	add(partitionSection(snap, cfg))
+	add(keySendSection(snap.KeySends))
+	return out, nil
+}
+
+func f1(v float64) string { return fmt.Sprintf("%.1f", v) }
+
+func summarySection(r results.Result, cfg config.Config) section {
+	snap := r.Snapshot
+	s := section{Title: "Load Test Summary"}
+	fact := func(k, format string, args ...any) {
+		s.Facts = append(s.Facts, [2]string{k, fmt.Sprintf(format, args...)})
+	}
+	fact("Test", "%s", r.TestName)
+	fact("Broker", "%s", r.ServiceURL)
+	if r.RunID != "" {
+		fact("Run ID", "%s", r.RunID)
+	}
+	if d := cfg.Execution.Duration; d != "" {
+		fact("Duration", "%s (elapsed %.1fs)", d, snap.ElapsedSec)
+	} else {
+		fact("Duration", "elapsed %.1fs", snap.ElapsedSec)
+	}
+	fact("Messages", "sent=%d  received=%d  errors=%d", snap.MessagesSent, snap.MessagesReceived, snap.Errors)
+	if snap.StaleMessages > 0 {
+		fact("Stale", "%d messages from other runs excluded from received, latency and integrity", snap.StaleMessages)
+	}
+	fact("Throughput", "tx=%.1f msg/s  rx=%.1f msg/s", snap.ThroughputSent, snap.ThroughputRecv)
+	if snap.LatencySamples > 0 {
+		fact("Latency (ms)", "p50=%.1f  p95=%.1f  p99=%.1f  max=%.1f  samples=%d", snap.LatencyP50Ms, snap.LatencyP95Ms, snap.LatencyP99Ms, snap.LatencyMaxMs, snap.LatencySamples)
+	} else {
+		fact("Latency (ms)", "no samples (enable string/json payloads to measure)")
+	}
+	var late, unverifiable, failed, redeliv, cross uint64
+	lossBasis := "observed gaps"
+	inSLA := 0
+	for _, e := range snap.IntegrityBreakdown {
+		late += e.LateArrivals
+		unverifiable += e.Unverifiable
+		failed += e.FailedSends
+		redeliv += e.Redeliveries
+		cross += e.CrossConsumerDuplicates
+		if e.Reconciled {
+			lossBasis = "sent ledger"
+		}
+		if e.InSLA() {
+			inSLA++
+		}
+	}
+	fact("Integrity", "loss=%d  duplicates=%d  late=%d  failed_sends=%d  (loss vs %s)", snap.EstimatedLoss, snap.Duplicates, late, failed, lossBasis)
+	fact("Duplicates", "redeliveries=%d  cross_consumer=%d  unclassified=%d", redeliv, cross, snap.Duplicates-redeliv-cross)
+	if unverifiable > 0 {
+		fact("Unverifiable", "%d arrivals from gaps given up under heavy loss", unverifiable)
+	}
+	fact("Ordering", "out_of_order=%d  violations=%d", snap.OutOfOrder, snap.OrderingViolations)
+	if total := len(snap.IntegrityBreakdown); total > 0 {
+		fact("SLA", "keys_in_sla=%d  keys_out_sla=%d  total_keys=%d", inSLA, total-inSLA, total)
+	}
+	if rd := snap.Readiness; rd != nil {
+		fact("Readiness", "ready=%d/%d  failed=%d  pending=%d  timed_out=%v  wait=%.0fms", rd.Ready, rd.Expected, rd.Failed, rd.Pending, rd.TimedOut, rd.WaitMs)
+	}
+	return s
+}
+
+func thresholdSection(vs []thresholds.Verdict) section {
+	s := section{Title: "Thresholds", Header: []string{"Result", "Threshold", "Limit", "Value"}}
+	if len(vs) == 0 {
+		return s
+	}
+	for _, v := range vs {
+		result, value := "PASS", fmt.Sprintf("%g", v.Value)
+		if v.Error != "" {
+			result, value = "FAIL", v.Error
+		} else if !v.Pass {
+			result = "FAIL"
+		}
+		s.Rows = append(s.Rows, []string{result, v.Name, fmt.Sprintf("%s %g", v.Op, v.Limit), value})
+	}
+	if n := thresholds.Failed(vs); n > 0 {
+		s.Notes = append(s.Notes, fmt.Sprintf("%d of %d thresholds failed", n, len(vs)))
+	} else {
+		s.Notes = append(s.Notes, fmt.Sprintf("All %d thresholds passed", len(vs)))
+	}
+	return s
+}
+
+// errorCategorySection lists errors per category and the busiest group and code combinations.
+func errorCategorySection(snap metrics.Snapshot) section {
+	s := section{Title: "Errors", Header: []string{"Category", "Role", "Group", "Code", "Count"}}
+	if snap.Errors == 0 {
+		return s
+	}
+	cats := make([]string, 0, len(snap.ErrorCategories))
+	for cat := range snap.ErrorCategories {
+		cats = append(cats, cat)
+	}
+	sort.Strings(cats)
+	// the busiest interval shows whether errors came in a burst or steadily
+	peak := map[string]float64{}
+	for _, iv := range snap.Intervals {
+		for cat, r := range iv.ErrorRates {
+			peak[cat] = max(peak[cat], r)
+		}
+	}
+	for _, cat := range cats {
+		v := fmt.Sprintf("%d", snap.ErrorCategories[cat])
+		if r, ok := peak[cat]; ok {
+			v += fmt.Sprintf("  (peak %.1f/s)", r)
+		}
+		s.Facts = append(s.Facts, [2]string{cat, v})
+	}
+	for i, e := range snap.ErrorBreakdown {
+		if i == 5 {
+			s.Notes = append(s.Notes, fmt.Sprintf("%d more group and code combinations", len(snap.ErrorBreakdown)-i))
+			break
+		}
+		s.Rows = append(s.Rows, []string{e.Category, e.Role, e.Group, e.Code, fmt.Sprintf("%d", e.Count)})
+	}
+	return s
+}
+
+func errorMessageSection(es []metrics.ErrorMessage) section {
+	s := section{Title: "Top errors", Header: []string{"Category", "Count", "First", "Last", "Message"}}
+	for _, e := range es {
+		s.Rows = append(s.Rows, []string{e.Category, fmt.Sprintf("%d", e.Count), e.First.Format("15:04:05"), e.Last.Format("15:04:05"), e.Message})
+	}
+	return s
+}
+
+// worstKeySection lists the worst keys out of SLA, capped at worstKeys rows.
+func worstKeySection(entries []metrics.IntegrityEntry) section {
+	s := section{Title: "Worst keys", Header: []string{"Topic", "Subscription", "Producer", "Loss", "Dup", "Redeliv", "Cross", "OOO", "Range", "Seen", "Expected"}}
+	out := 0
+	for _, k := range sortedKeys(entries) {
+		if k.InSLA() {
+			continue
+		}
+		out++
+		if out > worstKeys {
+			continue
+		}
+		s.Rows = append(s.Rows, []string{k.Topic, k.Subscription, k.Producer,
+			fmt.Sprintf("%d", k.Loss), fmt.Sprintf("%d", k.Duplicates), fmt.Sprintf("%d", k.Redeliveries),
+			fmt.Sprintf("%d", k.CrossConsumerDuplicates), fmt.Sprintf("%d", k.OutOfOrder),
+			fmt.Sprintf("%d..%d", k.Min, k.Max), fmt.Sprintf("%d", k.UniqueSeen), fmt.Sprintf("%d", k.Expected)})
+	}
+	if out > worstKeys {
+		s.Notes = append(s.Notes, fmt.Sprintf("%d more keys out of SLA; see the integrity breakdown in the JSON export", out-worstKeys))
+	}
+	return s
+}
+
+// orderingSection lists keys whose subscription and topic layout promise order but arrived out of it.
+func orderingSection(entries []metrics.IntegrityEntry) section {
+	s := section{Title: "Ordering violations", Header: []string{"Topic", "Subscription", "Producer", "Out of order", "Max distance"}}
+	for _, e := range entries {
+		if e.OrderingViolations > 0 {
+			s.Rows = append(s.Rows, []string{e.Topic, e.Subscription, e.Producer, fmt.Sprintf("%d", e.OrderingViolations), fmt.Sprintf("%d", e.MaxReorderDistance)})
+		}
+	}
+	return s
+}
+
+func setupSection(ss []metrics.SetupStats) section {
+	s := section{Title: "Setup", Header: []string{"Role", "Group", "Workers", "Failed", "p50 ms", "p95 ms", "p99 ms", "max ms", "Attempts p95", "Attempts max", "First msg p50 ms", "First msg max ms"}}
+	for _, st := range ss {
+		l, f := st.SetupMs, st.FirstMessageMs
+		first50, firstMax := "-", "-"
+		if f.Count > 0 {
+			first50, firstMax = f1(f.P50), f1(f.Max)
+		}
+		s.Rows = append(s.Rows, []string{st.Role, st.Group, fmt.Sprintf("%d", st.Workers), fmt.Sprintf("%d", st.Failures),
+			f1(l.P50), f1(l.P95), f1(l.P99), f1(l.Max), fmt.Sprintf("%.0f", st.Attempts.P95), fmt.Sprintf("%.0f", st.Attempts.Max), first50, firstMax})
+	}
+	return s
+}
+
+func reconnectSection(rs []metrics.ReconnectStats) section {
+	s := section{Title: "Reconnects", Header: []string{"Role", "Group", "Disconnects", "Reconnects", "Unrecovered", "Downtime s", "p50 ms", "p95 ms", "max ms"}}
+	for _, r := range rs {
+		d := r.DowntimeMs
+		s.Rows = append(s.Rows, []string{r.Role, r.Group, fmt.Sprintf("%d", r.Disconnects), fmt.Sprintf("%d", r.Reconnects),
+			fmt.Sprintf("%d", r.Unrecovered), f1(r.DowntimeSec), f1(d.P50), f1(d.P95), f1(d.Max)})
+	}
+	return s
+}
+
+func producerCreateSection(ps []metrics.ProducerCreateStats) section {
+	s := section{Title: "Producer creates", Header: []string{"Group", "Creates", "Failures", "p50 ms", "p95 ms", "p99 ms", "max ms", "Failed max ms",
+		"Closes", "Close failures", "Close p50 ms", "Close p99 ms"}}
+	for _, p := range ps {
+		l, cl := p.CreateMs, p.CloseMs
+		s.Rows = append(s.Rows, []string{p.Group, fmt.Sprintf("%d", p.Creates), fmt.Sprintf("%d", p.Failures),
+			f1(l.P50), f1(l.P95), f1(l.P99), f1(l.Max), f1(p.FailedCreateMs.Max),
+			fmt.Sprintf("%d", p.Closes), fmt.Sprintf("%d", p.CloseFailures), f1(cl.P50), f1(cl.P99)})
+	}
+	return s
+}
+
+func producerGroupSection(gs []metrics.ProducerGroupStats) section {
+	s := section{Title: "Producer groups", Header: []string{"Group", "Workers", "Sent", "tx msg/s", "Target msg/s"}}
+	for _, g := range gs {
+		target := "unlimited"
+		if g.TargetRate > 0 {
+			target = f1(g.TargetRate)
+		}
+		s.Rows = append(s.Rows, []string{g.Name, fmt.Sprintf("%d", g.Workers), fmt.Sprintf("%d", g.Sent), f1(g.ThroughputSent), target})
+	}
+	return s
+}
+
+func consumerGroupSection(gs []metrics.ConsumerGroupStats) section {
+	s := section{Title: "Consumer groups", Header: []string{"Group", "Workers", "Slots", "Received", "Busy s", "Utilization", "Ack mode", "Acked", "Ack failed", "Unacked", "p50 ms", "p99 ms"}}
+	for _, g := range gs {
+		s.Rows = append(s.Rows, []string{g.Name, fmt.Sprintf("%d", g.Workers), fmt.Sprintf("%d", g.Slots), fmt.Sprintf("%d", g.Received),
+			f1(g.BusySec), fmt.Sprintf("%.1f%%", g.Utilization*100), g.AckMode, fmt.Sprintf("%d", g.Acked),
+			fmt.Sprintf("%d", g.AckFailed), fmt.Sprintf("%d", g.AckSkipped), f1(g.LatencyMs.P50), f1(g.LatencyMs.P99)})
+	}
+	return s
+}
+
+func failoverSection(fs []metrics.FailoverStats) section {
+	s := section{Title: "Failovers", Header: []string{"Group", "Kills", "Takeovers", "Gap p50 ms", "Gap p95 ms", "Gap max ms", "Lost", "Duplicated"}}
+	for _, f := range fs {
+		g := f.GapMs
+		s.Rows = append(s.Rows, []string{f.Group, fmt.Sprintf("%d", f.Kills), fmt.Sprintf("%d", f.Takeovers),
+			f1(g.P50), f1(g.P95), f1(g.Max), fmt.Sprintf("%d", f.Lost), fmt.Sprintf("%d", f.Duplicated)})
+	}
+	return s
+}
+
+// partitionSection lists per-partition receive counts and latency, with per-topic skew as facts.
+func partitionSection(snap metrics.Snapshot, cfg config.Config) section {
+	s := section{Title: "Partitions", Header: []string{"Topic", "Partition", "Received", "p50 ms", "p95 ms", "p99 ms", "max ms"}}
+	if len(snap.Partitions) == 0 {
+		return s
+	}
+	configured := map[string]int{}
+	for _, t := range cfg.Topics {
+		configured[t.Name] = t.Partitions
+	}
+	for _, sk := range snap.PartitionSkew {
+		ratio := "n/a"
+		switch {
+		case sk.Unbounded():
+			ratio = "inf"
+		case sk.MinReceived > 0:
+			ratio = fmt.Sprintf("%.2f", sk.MaxMinRatio)
+		}
+		observed := fmt.Sprintf("%d", sk.Partitions-sk.Idle)
+		if n, ok := configured[sk.Topic]; ok {
+			observed = fmt.Sprintf("%d/%d", sk.Partitions-sk.Idle, n)
+		}
+		s.Facts = append(s.Facts, [2]string{sk.Topic, fmt.Sprintf("observed=%s max=%d min=%d max/min=%s", observed, sk.MaxReceived, sk.MinReceived, ratio)})
+	}
+	for _, ps := range snap.Partitions {
+		l := ps.LatencyMs
+		s.Rows = append(s.Rows, []string{ps.Topic, ps.Partition, fmt.Sprintf("%d", ps.Received), f1(l.P50), f1(l.P95), f1(l.P99), f1(l.Max)})
+	}
+	return s
+}
+
+// keySendSection lists per-topic routing key spread: distinct keys and the hottest key share.
+// KeySends is sorted by topic with the busiest key first.
+func keySendSection(ks []metrics.KeySendCount) section {
+	s := section{Title: "Routing keys", Header: []string{"Topic", "Keys", "Sent", "Hottest key", "Hottest sent", "Share"}}
+	for i := 0; i < len(ks); {
+		j := i
+		var total uint64
+		for j < len(ks) && ks[j].Topic == ks[i].Topic {
+			total += ks[j].Sent
+			j++
+		}
+		hot := ks[i]
+		share := 0.0
+		if total > 0 {
+			share = float64(hot.Sent) * 100 / float64(total)
+		}
+		s.Rows = append(s.Rows, []string{hot.Topic, fmt.Sprintf("%d", j-i), fmt.Sprintf("%d", total), hot.Key, fmt.Sprintf("%d", hot.Sent), fmt.Sprintf("%.1f%%", share)})
+		i = j
+	}
+	return s
+}
+
+// escapePipes keeps cell text from breaking Markdown table columns.
+func escapePipes(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
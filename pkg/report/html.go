@@ -0,0 +1,215 @@
+// Package report renders the results of a run for people: text, Markdown and HTML reports.
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+)
+
+// worstKeys is how many out-of-SLA integrity keys are highlighted at the top of the table.
+const worstKeys = 10
+
+// htmlData is what the page template renders.
+type htmlData struct {
+	results.Result
+	Generated      time.Time
+	ThroughputSVG  template.HTML
+	LatencySVG     template.HTML
+	PercentilesSVG template.HTML
+	Keys           []keyRow
+	KeysInSLA      int
+	Passed         int
+}
+
+type keyRow struct {
+	metrics.IntegrityEntry
+	Worst bool
+}
+
+// WriteHTML renders r as a single HTML page with inline styles and SVG charts; it fetches nothing.
+func WriteHTML(w io.Writer, r results.Result) error {
+	snap := r.Snapshot
+	d := htmlData{Result: r, Generated: time.Now()}
+
+	var x []float64
+	var tx, rx, p50, p95, p99 []float64
+	for _, s := range snap.Intervals {
+		x = append(x, s.ElapsedSec)
+		tx = append(tx, s.TxRate)
+		rx = append(rx, s.RxRate)
+		p50 = append(p50, s.LatencyMs.P50)
+		p95 = append(p95, s.LatencyMs.P95)
+		p99 = append(p99, s.LatencyMs.P99)
+	}
+	seconds := func(v float64) string { return fmt.Sprintf("%.0fs", v) }
+	d.ThroughputSVG = lineChart(x, []series{{"tx", "#2b6cb0", tx}, {"rx", "#2f855a", rx}}, seconds, "msg/s")
+	d.LatencySVG = lineChart(x, []series{{"p50", "#2f855a", p50}, {"p95", "#d69e2e", p95}, {"p99", "#c53030", p99}}, seconds, "ms")
+
+	// percentiles are spaced evenly so the tail is as readable as the median
+	var px, pms []float64
+	var plabels []string
+	for i, p := range snap.LatencyCurve {
+		px = append(px, float64(i))
+		pms = append(pms, p.Ms)
+		plabels = append(plabels, fmt.Sprintf("p%g", p.Percentile))
+	}
+	d.PercentilesSVG = lineChart(px, []series{{"latency", "#6b46c1", pms}}, func(v float64) string { return plabels[int(v)] }, "ms")
+
+	d.Keys = sortedKeys(snap.IntegrityBreakdown)
+	for _, k := range d.Keys {
+		if k.InSLA() {
+			d.KeysInSLA++
+		}
+	}
+	for _, v := range r.Thresholds {
+		if v.Pass && v.Error == "" {
+			d.Passed++
+		}
+	}
+	return pageTemplate.Execute(w, d)
+}
+
+// sortedKeys orders integrity entries worst first (loss, then unexpected duplicates, then
+// ordering violations) and marks the first worstKeys entries out of SLA.
+func sortedKeys(entries []metrics.IntegrityEntry) []keyRow {
+	bd := append([]metrics.IntegrityEntry(nil), entries...)
+	sort.SliceStable(bd, func(i, j int) bool {
+		if bd[i].Loss != bd[j].Loss {
+			return bd[i].Loss > bd[j].Loss
+		}
+		if di, dj := bd[i].UnexpectedDuplicates(), bd[j].UnexpectedDuplicates(); di != dj {
+			return di > dj
+		}
+		return bd[i].OrderingViolations > bd[j].OrderingViolations
+	})
+	rows := make([]keyRow, len(bd))
+	for i, e := range bd {
+		rows[i] = keyRow{IntegrityEntry: e, Worst: i < worstKeys && !e.InSLA()}
+	}
+	return rows
+}
+
+var pageTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"f1":  func(v float64) string { return fmt.Sprintf("%.1f", v) },
+	"pct": func(v float64) string { return fmt.Sprintf("%.1f%%", v*100) },
+	"ts":  func(t time.Time) string { return t.Format("2006-01-02 15:04:05") },
+}).Parse(pageHTML))
+
+const pageHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.TestName}} — load test report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 1100px; color: #1a202c; padding: 0 1rem; }
+h1 { margin-bottom: .2rem; } h2 { margin-top: 2rem; border-bottom: 1px solid #e2e8f0; padding-bottom: .3rem; }
+.muted { color: #718096; }
+table { border-collapse: collapse; width: 100%; font-size: .9rem; margin: .5rem 0; }
+th, td { border-bottom: 1px solid #e2e8f0; padding: .3rem .5rem; text-align: right; }
+th:first-child, td:first-child, td.l { text-align: left; }
+th { background: #f7fafc; }
+tr.bad td { background: #fff5f5; color: #c53030; }
+tr.worst td { background: #fed7d7; color: #9b2c2c; font-weight: 600; }
+.pass { color: #2f855a; font-weight: 600; } .fail { color: #c53030; font-weight: 600; }
+.cards { display: flex; flex-wrap: wrap; gap: .8rem; }
+.card { border: 1px solid #e2e8f0; border-radius: 6px; padding: .6rem 1rem; min-width: 9rem; }
+.card b { display: block; font-size: 1.3rem; }
+svg.chart { width: 100%; height: auto; }
+svg .grid { stroke: #e2e8f0; } svg .tick { font-size: 11px; fill: #4a5568; }
+pre { background: #f7fafc; padding: 1rem; overflow-x: auto; font-size: .85rem; }
+</style>
+</head>
+<body>
+<h1>{{.TestName}}</h1>
+<p class="muted">{{if .Description}}{{.Description}} · {{end}}{{.ServiceURL}}{{if .RunID}} · run {{.RunID}}{{end}} · generated {{ts .Generated}}</p>
+
+<div class="cards">
+<div class="card">Duration<b>{{f1 .DurationSec}}s</b></div>
+<div class="card">Sent<b>{{.Snapshot.MessagesSent}}</b></div>
+<div class="card">Received<b>{{.Snapshot.MessagesReceived}}</b></div>
+<div class="card">Errors<b>{{.Snapshot.Errors}}</b></div>
+<div class="card">Throughput tx/rx<b>{{f1 .Snapshot.ThroughputSent}} / {{f1 .Snapshot.ThroughputRecv}}</b></div>
+<div class="card">Latency p99<b>{{f1 .Snapshot.LatencyP99Ms}} ms</b></div>
+<div class="card">Loss / duplicates<b>{{.Snapshot.EstimatedLoss}} / {{.Snapshot.Duplicates}}</b></div>
+</div>
+
+{{with .Thresholds}}
+<h2>Thresholds <span class="muted">({{$.Passed}} of {{len .}} passed)</span></h2>
+<table>
+<tr><th>Threshold</th><th>Scope</th><th>Limit</th><th>Value</th><th>Result</th></tr>
+{{range .}}<tr{{if or .Error (not .Pass)}} class="bad"{{end}}><td>{{.Name}}</td><td class="l">{{.Scope}}</td><td>{{.Op}} {{.Limit}}</td><td>{{if .Error}}{{.Error}}{{else}}{{.Value}}{{end}}</td><td>{{if and .Pass (not .Error)}}<span class="pass">PASS</span>{{else}}<span class="fail">FAIL</span>{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>Throughput over time</h2>
+{{.ThroughputSVG}}
+<h2>Latency over time</h2>
+{{.LatencySVG}}
+<h2>Latency distribution</h2>
+{{.PercentilesSVG}}
+<table>
+<tr><th>Samples</th><th>p50</th><th>p95</th><th>p99</th><th>max</th></tr>
+<tr><td>{{.Snapshot.LatencySamples}}</td><td>{{f1 .Snapshot.LatencyP50Ms}}</td><td>{{f1 .Snapshot.LatencyP95Ms}}</td><td>{{f1 .Snapshot.LatencyP99Ms}}</td><td>{{f1 .Snapshot.LatencyMaxMs}}</td></tr>
+</table>
+
+{{with .Snapshot.ProducerGroups}}
+<h2>Producer groups</h2>
+<table>
+<tr><th>Group</th><th>Workers</th><th>Sent</th><th>Throughput</th><th>Target</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.Workers}}</td><td>{{.Sent}}</td><td>{{f1 .ThroughputSent}}</td><td>{{if .TargetRate}}{{f1 .TargetRate}}{{else}}unlimited{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Snapshot.ConsumerGroups}}
+<h2>Consumer groups</h2>
+<table>
+<tr><th>Group</th><th>Workers</th><th>Received</th><th>Utilization</th><th>Ack mode</th><th>Acked</th><th>Ack failed</th><th>Unacked</th><th>p50 ms</th><th>p99 ms</th></tr>
+{{range .}}<tr><td>{{.Name}}</td><td>{{.Workers}}</td><td>{{.Received}}</td><td>{{pct .Utilization}}</td><td>{{.AckMode}}</td><td>{{.Acked}}</td><td>{{.AckFailed}}</td><td>{{.AckSkipped}}</td><td>{{f1 .LatencyMs.P50}}</td><td>{{f1 .LatencyMs.P99}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Snapshot.Setup}}
+<h2>Setup</h2>
+<table>
+<tr><th>Group</th><th>Role</th><th>Workers</th><th>Failed</th><th>Setup p50 ms</th><th>Setup max ms</th><th>First message p95 ms</th></tr>
+{{range .}}<tr><td>{{.Group}}</td><td>{{.Role}}</td><td>{{.Workers}}</td><td>{{.Failures}}</td><td>{{f1 .SetupMs.P50}}</td><td>{{f1 .SetupMs.Max}}</td><td>{{f1 .FirstMessageMs.P95}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Snapshot.Reconnects}}
+<h2>Reconnects</h2>
+<table>
+<tr><th>Group</th><th>Role</th><th>Disconnects</th><th>Reconnects</th><th>Unrecovered</th><th>Downtime s</th></tr>
+{{range .}}<tr{{if .Unrecovered}} class="bad"{{end}}><td>{{.Group}}</td><td>{{.Role}}</td><td>{{.Disconnects}}</td><td>{{.Reconnects}}</td><td>{{.Unrecovered}}</td><td>{{f1 .DowntimeSec}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Snapshot.TopErrors}}
+<h2>Errors</h2>
+<table>
+<tr><th>Message</th><th>Category</th><th>Count</th><th>First</th><th>Last</th></tr>
+{{range .}}<tr><td>{{.Message}}</td><td>{{.Category}}</td><td>{{.Count}}</td><td>{{ts .First}}</td><td>{{ts .Last}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .Keys}}
+<h2>Integrity <span class="muted">({{$.KeysInSLA}} of {{len .}} keys in SLA)</span></h2>
+<table>
+<tr><th>Topic</th><th>Subscription</th><th>Producer</th><th>Seen</th><th>Expected</th><th>Loss</th><th>Duplicates</th><th>Redeliveries</th><th>Cross-consumer</th><th>Out of order</th><th>Violations</th></tr>
+{{range .}}<tr{{if .Worst}} class="worst"{{else if not .InSLA}} class="bad"{{end}}><td>{{.Topic}}</td><td class="l">{{.Subscription}}</td><td class="l">{{.Producer}}</td><td>{{.UniqueSeen}}</td><td>{{.Expected}}</td><td>{{.Loss}}</td><td>{{.Duplicates}}</td><td>{{.Redeliveries}}</td><td>{{.CrossConsumerDuplicates}}</td><td>{{.OutOfOrder}}</td><td>{{.OrderingViolations}}</td></tr>
+{{end}}</table>
+{{end}}
+
+{{with .ConfigYAML}}
+<h2>Configuration</h2>
+<pre>{{.}}</pre>
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
+)
+
+func sampleResult() results.Result {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return results.Result{
+		TestName:    "soak",
+		RunID:       "r1",
+		ServiceURL:  "127.0.0.1:6650",
+		DurationSec: 10,
+		ConfigYAML:  "test_name: soak\n",
+		Snapshot: metrics.Snapshot{
+			MessagesSent: 100,
+			Intervals: []metrics.IntervalSample{
+				{At: at, ElapsedSec: 5, TxRate: 10, RxRate: 9, LatencyMs: metrics.Distribution{P50: 1, P95: 2, P99: 3}},
+				{At: at.Add(5 * time.Second), ElapsedSec: 10, TxRate: 12, RxRate: 12, LatencyMs: metrics.Distribution{P50: 1, P95: 2, P99: 4}},
+			},
+			LatencyCurve: []metrics.PercentilePoint{{Percentile: 50, Ms: 1}, {Percentile: 99, Ms: 4}},
+			IntegrityBreakdown: []metrics.IntegrityEntry{
+				{Topic: "/default/ok", Subscription: "s", Producer: "p-0"},
+				{Topic: "/default/<lossy>", Subscription: "s", Producer: "p-1", Loss: 7},
+			},
+			ProducerGroups: []metrics.ProducerGroupStats{{Name: "writers", Workers: 2, Sent: 100}},
+		},
+		Thresholds: []thresholds.Verdict{
+			{Name: "p99 under 20ms", Op: "<", Limit: 20, Value: 4, Pass: true},
+			{Name: "no loss", Op: "==", Limit: 0, Value: 7},
+		},
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, sampleResult()); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	page := buf.String()
+	for _, want := range []string{
+		"<svg", "<polyline", "writers", "p99 under 20ms", "1 of 2 passed",
+		"1 of 2 keys in SLA", `class="worst"`, "test_name: soak", "&lt;lossy&gt;",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page misses %q", want)
+		}
+	}
+	// self-contained: no external scripts, styles or images
+	for _, bad := range []string{"<script", "<link", "http://", "https://"} {
+		if strings.Contains(page, bad) {
+			t.Errorf("page must not reference %q", bad)
+		}
+	}
+}
+
+func TestWriteHTMLWithoutSamples(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, results.Result{TestName: "empty"}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No samples.") {
+		t.Fatal("expected a placeholder for missing charts")
+	}
+}
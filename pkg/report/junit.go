@@ -0,0 +1,180 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+)
+
+// JUnit XML as read by common CI test reporters.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnitRenderer writes JUnit XML with one suite each for thresholds, integrity keys and
+// producer/consumer groups, so regressions show up in CI test dashboards.
+type JUnitRenderer struct{}
+
+// Render implements Renderer.
+func (JUnitRenderer) Render(w io.Writer, r results.Result) error {
+	cfg, err := savedConfig(r)
+	if err != nil {
+		return err
+	}
+	elapsed := fmt.Sprintf("%.3f", r.DurationSec)
+	var timestamp string
+	if !r.StartedAt.IsZero() {
+		timestamp = r.StartedAt.UTC().Format("2006-01-02T15:04:05")
+	}
+	doc := junitSuites{Name: r.TestName, Time: elapsed}
+	for _, s := range []junitSuite{thresholdSuite(r), integritySuite(r), groupSuite(r, cfg.Execution.Mode == "idle")} {
+		if len(s.Cases) == 0 {
+			continue
+		}
+		s.Time, s.Timestamp = elapsed, timestamp
+		s.Tests = len(s.Cases)
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				s.Failures++
+			}
+		}
+		if r.RunID != "" {
+			s.Properties = append(s.Properties, junitProperty{Name: "run_id", Value: r.RunID})
+		}
+		s.Properties = append(s.Properties, junitProperty{Name: "service_url", Value: r.ServiceURL})
+		doc.Tests += s.Tests
+		doc.Failures += s.Failures
+		doc.Suites = append(doc.Suites, s)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func newCase(r results.Result, suite, name string) junitCase {
+	return junitCase{Name: name, Classname: r.TestName + "." + suite, Time: "0"}
+}
+
+func fail(c *junitCase, kind, message string) {
+	c.Failure = &junitFailure{Message: message, Type: kind, Text: message}
+}
+
+func thresholdSuite(r results.Result) junitSuite {
+	s := junitSuite{Name: r.TestName + ".thresholds"}
+	for _, v := range r.Thresholds {
+		c := newCase(r, "thresholds", v.Name)
+		switch {
+		case v.Error != "":
+			fail(&c, "threshold", fmt.Sprintf("%s could not be measured: %s", v.Metric, v.Error))
+		case !v.Pass:
+			fail(&c, "threshold", fmt.Sprintf("%s = %g, want %s %g", v.Metric, v.Value, v.Op, v.Limit))
+		default:
+			c.SystemOut = fmt.Sprintf("%s = %g (%s %g)", v.Metric, v.Value, v.Op, v.Limit)
+		}
+		s.Cases = append(s.Cases, c)
+	}
+	return s
+}
+
+// integritySuite has one case per topic+subscription+producer key, failing when the key is out of SLA.
+func integritySuite(r results.Result) junitSuite {
+	s := junitSuite{Name: r.TestName + ".integrity"}
+	for _, e := range r.Snapshot.IntegrityBreakdown {
+		c := newCase(r, "integrity", fmt.Sprintf("%s | %s | %s", e.Topic, e.Subscription, e.Producer))
+		measured := fmt.Sprintf("loss=%d unexpected_duplicates=%d ordering_violations=%d seen=%d expected=%d",
+			e.Loss, e.UnexpectedDuplicates(), e.OrderingViolations, e.UniqueSeen, e.Expected)
+		if e.InSLA() {
+			c.SystemOut = measured
+		} else {
+			fail(&c, "integrity", measured)
+		}
+		s.Cases = append(s.Cases, c)
+	}
+	return s
+}
+
+// groupSuite has one case per producer and consumer group. A group fails when a worker could not
+// be set up or, outside idle mode, when it moved no messages at all.
+func groupSuite(r results.Result, idle bool) junitSuite {
+	s := junitSuite{Name: r.TestName + ".groups"}
+	snap := r.Snapshot
+	setupFailures := map[string]int{}
+	for _, st := range snap.Setup {
+		setupFailures[st.Role+"/"+st.Group] += st.Failures
+	}
+	errs := map[string]uint64{}
+	for _, e := range snap.ErrorBreakdown {
+		errs[e.Role+"/"+e.Group] += e.Count
+	}
+	check := func(c *junitCase, role, group string, moved uint64, measured string) {
+		var problems []string
+		if n := setupFailures[role+"/"+group]; n > 0 {
+			problems = append(problems, fmt.Sprintf("%d workers failed setup", n))
+		}
+		if moved == 0 && !idle {
+			problems = append(problems, "no messages")
+		}
+		measured = fmt.Sprintf("%s errors=%d", measured, errs[role+"/"+group])
+		if len(problems) > 0 {
+			fail(c, "group", strings.Join(problems, "; ")+": "+measured)
+		} else {
+			c.SystemOut = measured
+		}
+	}
+	for _, g := range snap.ProducerGroups {
+		c := newCase(r, "groups", "producer "+g.Name)
+		check(&c, metrics.SetupProducer, g.Name, g.Sent, fmt.Sprintf("workers=%d sent=%d tx=%.1f msg/s", g.Workers, g.Sent, g.ThroughputSent))
+		s.Cases = append(s.Cases, c)
+	}
+	for _, g := range snap.ConsumerGroups {
+		c := newCase(r, "groups", "consumer "+g.Name)
+		check(&c, metrics.SetupConsumer, g.Name, g.Received, fmt.Sprintf("workers=%d received=%d p99=%.1fms", g.Workers, g.Received, g.LatencyMs.P99))
+		s.Cases = append(s.Cases, c)
+	}
+	return s
+}
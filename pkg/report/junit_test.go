@@ -0,0 +1,102 @@
+package report
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+)
+
+func TestJUnitRenderer(t *testing.T) {
+	r := sampleResult()
+	r.StartedAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	r.Snapshot.ConsumerGroups = []metrics.ConsumerGroupStats{{Name: "readers", Workers: 1}}
+	var buf bytes.Buffer
+	if err := (JUnitRenderer{}).Render(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Fatalf("missing xml header:\n%s", buf.String())
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parse: %v\n%s", err, buf.String())
+	}
+	// 2 thresholds + 2 integrity keys + 1 producer group + 1 consumer group
+	if doc.Tests != 6 || doc.Failures != 3 || doc.Time != "10.000" {
+		t.Fatalf("suites tests=%d failures=%d time=%s", doc.Tests, doc.Failures, doc.Time)
+	}
+	if len(doc.Suites) != 3 {
+		t.Fatalf("suites got %d", len(doc.Suites))
+	}
+	failures := map[string]string{}
+	for _, s := range doc.Suites {
+		if s.Timestamp != "2025-01-02T03:04:05" {
+			t.Errorf("suite %s timestamp %q", s.Name, s.Timestamp)
+		}
+		for _, c := range s.Cases {
+			if c.Failure != nil {
+				failures[c.Name] = c.Failure.Message
+			}
+		}
+	}
+	for name, want := range map[string]string{
+		"no loss":                    "= 7, want == 0",
+		"/default/<lossy> | s | p-1": "loss=7",
+		"consumer readers":           "no messages",
+	} {
+		if !strings.Contains(failures[name], want) {
+			t.Errorf("case %q failure %q, want it to contain %q", name, failures[name], want)
+		}
+	}
+}
+
+func TestJUnitIdleGroupsPass(t *testing.T) {
+	r := sampleResult()
+	r.ConfigYAML = "execution:\n  mode: idle\n"
+	r.Snapshot.ProducerGroups[0].Sent = 0
+	var buf bytes.Buffer
+	if err := (JUnitRenderer{}).Render(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "no messages") {
+		t.Fatalf("idle runs move no messages on purpose:\n%s", buf.String())
+	}
+}
+
+func TestJUnitGroupErrorsKeyedByRole(t *testing.T) {
+	r := sampleResult()
+	name := r.Snapshot.ProducerGroups[0].Name
+	r.Snapshot.ConsumerGroups = []metrics.ConsumerGroupStats{{Name: name, Workers: 1, Received: 1}}
+	r.Snapshot.ErrorBreakdown = []metrics.ErrorCount{{Category: metrics.ErrAck, Role: metrics.SetupConsumer, Group: name, Code: "Unknown", Count: 4}}
+	var buf bytes.Buffer
+	if err := (JUnitRenderer{}).Render(&buf, r); err != nil {
+		t.Fatal(err)
+	}
+	var doc junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("parse: %v\n%s", err, buf.String())
+	}
+	out := map[string]string{}
+	for _, s := range doc.Suites {
+		for _, c := range s.Cases {
+			out[c.Name] = c.SystemOut
+		}
+	}
+	if !strings.Contains(out["consumer "+name], "errors=4") || !strings.Contains(out["producer "+name], "errors=0") {
+		t.Fatalf("errors not kept apart per role: producer %q consumer %q", out["producer "+name], out["consumer "+name])
+	}
+}
+
+func TestRenderersRejectBrokenConfig(t *testing.T) {
+	r := sampleResult()
+	r.ConfigYAML = "execution: [\n"
+	for _, rd := range []Renderer{TextRenderer{}, MarkdownRenderer{}, JUnitRenderer{}} {
+		if err := rd.Render(&bytes.Buffer{}, r); err == nil || !strings.Contains(err.Error(), "parse saved config") {
+			t.Errorf("%T: got %v, want a parse error", rd, err)
+		}
+	}
+}
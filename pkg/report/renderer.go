@@ -0,0 +1,120 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+)
+
+// Renderer writes the final report of a run, live or loaded from a saved result.
+type Renderer interface {
+	Render(w io.Writer, r results.Result) error
+}
+
+// Formats lists the names accepted by NewRenderer.
+var Formats = []string{"text", "markdown", "html", "junit"}
+
+// NewRenderer returns the renderer for format: text, markdown (or md), html or junit.
+func NewRenderer(format string) (Renderer, error) {
+	switch format {
+	case "", "text":
+		return TextRenderer{}, nil
+	case "markdown", "md":
+		return MarkdownRenderer{}, nil
+	case "html":
+		return HTMLRenderer{}, nil
+	case "junit":
+		return JUnitRenderer{}, nil
+	}
+	return nil, fmt.Errorf("unknown report format %q (want %s)", format, strings.Join(Formats, "|"))
+}
+
+// TextRenderer prints aligned plain-text tables for terminals.
+type TextRenderer struct{}
+
+// Render implements Renderer.
+func (TextRenderer) Render(w io.Writer, r results.Result) error {
+	sections, err := buildSections(r)
+	if err != nil {
+		return err
+	}
+	for i, s := range sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "===== %s =====\n", s.Title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, f := range s.Facts {
+			fmt.Fprintf(tw, "%s:\t%s\n", f[0], f[1])
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if len(s.Rows) > 0 {
+			if len(s.Facts) > 0 {
+				fmt.Fprintln(w)
+			}
+			tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, strings.ToUpper(strings.Join(s.Header, "\t")))
+			for _, row := range s.Rows {
+				fmt.Fprintln(tw, strings.Join(row, "\t"))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+		for _, n := range s.Notes {
+			fmt.Fprintln(w, n)
+		}
+	}
+	return nil
+}
+
+// MarkdownRenderer prints GitHub-flavored Markdown tables for PR descriptions and wikis.
+type MarkdownRenderer struct{}
+
+// Render implements Renderer.
+func (MarkdownRenderer) Render(w io.Writer, r results.Result) error {
+	sections, err := buildSections(r)
+	if err != nil {
+		return err
+	}
+	for i, s := range sections {
+		level := "###"
+		if i == 0 {
+			level = "##"
+		}
+		fmt.Fprintf(w, "%s %s\n\n", level, s.Title)
+		for _, f := range s.Facts {
+			fmt.Fprintf(w, "- **%s:** %s\n", escapePipes(f[0]), escapePipes(f[1]))
+		}
+		if len(s.Facts) > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(s.Rows) > 0 {
+			fmt.Fprintf(w, "| %s |\n", strings.Join(s.Header, " | "))
+			fmt.Fprintf(w, "|%s\n", strings.Repeat("---|", len(s.Header)))
+			for _, row := range s.Rows {
+				cells := make([]string, len(row))
+				for j, c := range row {
+					cells[j] = escapePipes(c)
+				}
+				fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+			}
+			fmt.Fprintln(w)
+		}
+		for _, n := range s.Notes {
+			fmt.Fprintf(w, "_%s_\n\n", n)
+		}
+	}
+	return nil
+}
+
+// HTMLRenderer writes the self-contained HTML page of WriteHTML.
+type HTMLRenderer struct{}
+
+// Render implements Renderer.
+func (HTMLRenderer) Render(w io.Writer, r results.Result) error { return WriteHTML(w, r) }
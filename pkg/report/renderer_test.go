@@ -0,0 +1,82 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+)
+
+func TestNewRenderer(t *testing.T) {
+	for _, f := range append(Formats, "", "md") {
+		if _, err := NewRenderer(f); err != nil {
+			t.Errorf("format %q: %v", f, err)
+		}
+	}
+	if _, err := NewRenderer("pdf"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestTextRendererAlignsTables(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (TextRenderer{}).Render(&buf, sampleResult()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"===== Load Test Summary =====", "===== Thresholds =====", "===== Worst keys =====", "1 of 2 thresholds failed"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text report misses %q:\n%s", want, out)
+		}
+	}
+	// the loss column starts at the same offset in the header and in the row
+	lines := strings.Split(out, "\n")
+	var header, row string
+	for i, l := range lines {
+		if strings.HasPrefix(l, "TOPIC") && strings.Contains(l, "LOSS") {
+			header, row = l, lines[i+1]
+			break
+		}
+	}
+	if header == "" || strings.Index(header, "LOSS") != strings.Index(row, "7 ") {
+		t.Fatalf("worst keys table not aligned:\n%s\n%s", header, row)
+	}
+}
+
+func TestMarkdownRenderer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (MarkdownRenderer{}).Render(&buf, sampleResult()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"## Load Test Summary", "- **Test:** soak", "### Thresholds", "| Result | Threshold | Limit | Value |", "|---|---|---|---|", "| FAIL | no loss | == 0 | 7 |"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown report misses %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWorstKeysCapped(t *testing.T) {
+	var entries []metrics.IntegrityEntry
+	for i := 0; i < worstKeys+5; i++ {
+		entries = append(entries, metrics.IntegrityEntry{Topic: fmt.Sprintf("/default/t%d", i), Loss: uint64(i + 1)})
+	}
+	s := worstKeySection(entries)
+	if len(s.Rows) != worstKeys {
+		t.Fatalf("rows got %d want %d", len(s.Rows), worstKeys)
+	}
+	if s.Rows[0][0] != fmt.Sprintf("/default/t%d", worstKeys+4) {
+		t.Fatalf("worst key should come first, got %v", s.Rows[0])
+	}
+	if len(s.Notes) != 1 || !strings.HasPrefix(s.Notes[0], "5 more keys") {
+		t.Fatalf("notes got %v", s.Notes)
+	}
+}
+
+func TestEscapePipes(t *testing.T) {
+	if got := escapePipes("a|b\nc"); got != `a\|b c` {
+		t.Fatalf("got %q", got)
+	}
+}
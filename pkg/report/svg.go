@@ -0,0 +1,111 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"strings"
+)
+
+// Chart geometry in SVG user units; the viewBox scales it to the page width.
+const (
+	chartW    = 760
+	chartH    = 260
+	padLeft   = 56
+	padRight  = 16
+	padTop    = 16
+	padBottom = 36
+)
+
+// series is one line of a chart.
+type series struct {
+	Name  string
+	Color string
+	Y     []float64
+}
+
+// lineChart renders series against x as an inline SVG. xLabel formats the x axis ticks and
+// may be nil for plain numbers; unit labels the y axis.
+func lineChart(x []float64, ss []series, xLabel func(float64) string, unit string) template.HTML {
+	if len(x) == 0 {
+		return template.HTML(`<p class="muted">No samples.</p>`)
+	}
+	if xLabel == nil {
+		xLabel = func(v float64) string { return fmt.Sprintf("%g", v) }
+	}
+	xmin, xmax := x[0], x[len(x)-1]
+	if xmax == xmin {
+		xmax = xmin + 1
+	}
+	ymax := 0.0
+	for _, s := range ss {
+		for _, v := range s.Y {
+			ymax = math.Max(ymax, v)
+		}
+	}
+	ymax = niceCeil(ymax)
+	plotW := float64(chartW - padLeft - padRight)
+	plotH := float64(chartH - padTop - padBottom)
+	px := func(v float64) float64 { return padLeft + (v-xmin)/(xmax-xmin)*plotW }
+	py := func(v float64) float64 { return padTop + plotH - v/ymax*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg viewBox="0 0 %d %d" class="chart" role="img">`, chartW, chartH)
+	// horizontal grid with y ticks
+	for i := 0; i <= 4; i++ {
+		v := ymax * float64(i) / 4
+		y := py(v)
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="grid"/>`, padLeft, y, chartW-padRight, y)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="tick" text-anchor="end">%s</text>`, padLeft-6, y+4, formatTick(v))
+	}
+	// x ticks at up to 6 evenly spaced samples
+	step := (len(x) + 5) / 6
+	for i := 0; i < len(x); i += step {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" class="tick" text-anchor="middle">%s</text>`, px(x[i]), chartH-padBottom+16, template.HTMLEscapeString(xLabel(x[i])))
+	}
+	fmt.Fprintf(&b, `<text x="12" y="%d" class="tick" transform="rotate(-90 12 %d)" text-anchor="middle">%s</text>`, chartH/2, chartH/2, template.HTMLEscapeString(unit))
+	for _, s := range ss {
+		var pts []string
+		for i, v := range s.Y {
+			if i < len(x) {
+				pts = append(pts, fmt.Sprintf("%.1f,%.1f", px(x[i]), py(v)))
+			}
+		}
+		fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(pts, " "), s.Color)
+		if len(pts) == 1 {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, px(x[0]), py(s.Y[0]), s.Color)
+		}
+	}
+	// legend
+	for i, s := range ss {
+		lx := padLeft + 8 + i*110
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="10" height="10" fill="%s"/>`, lx, padTop, s.Color)
+		fmt.Fprintf(&b, `<text x="%d" y="%d" class="tick">%s</text>`, lx+14, padTop+9, template.HTMLEscapeString(s.Name))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// niceCeil rounds v up to 1, 2 or 5 times a power of ten so axis ticks are readable.
+func niceCeil(v float64) float64 {
+	if v <= 0 {
+		return 1
+	}
+	p := math.Pow(10, math.Floor(math.Log10(v)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if v <= m*p {
+			return m * p
+		}
+	}
+	return 10 * p
+}
+
+func formatTick(v float64) string {
+	switch {
+	case v >= 1e6:
+		return fmt.Sprintf("%gM", math.Round(v/1e5)/10)
+	case v >= 1e3:
+		return fmt.Sprintf("%gk", math.Round(v/1e2)/10)
+	}
+	return fmt.Sprintf("%g", math.Round(v*100)/100)
+}
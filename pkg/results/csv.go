@@ -0,0 +1,116 @@
+package results
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+)
+
+// CSV files written by WriteCSV, by suffix. Every file starts with test_name and run_id so
+// files from several runs can be concatenated; other columns follow the JSON keys.
+var (
+	intervalColumns = []string{"test_name", "run_id", "timestamp", "elapsed_sec", "sent", "received", "errors",
+		"tx_rate", "rx_rate", "error_rate", "error_rates", "latency_count", "latency_p50_ms", "latency_p95_ms", "latency_p99_ms", "latency_max_ms"}
+	groupColumns = []string{"test_name", "run_id", "role", "group", "workers", "sent", "received", "throughput",
+		"target_rate", "utilization", "ack_mode", "acked", "ack_failed", "unacked", "latency_p50_ms", "latency_p95_ms", "latency_p99_ms", "latency_max_ms"}
+	integrityColumns = []string{"test_name", "run_id", "topic", "subscription", "producer", "min", "max", "unique_seen",
+		"reconciled", "expected", "failed_sends", "loss", "duplicates", "redeliveries", "cross_consumer_duplicates",
+		"unclassified_duplicates", "late_arrivals", "out_of_order", "max_reorder_distance", "ordering_violations",
+		"ordering_expected", "redelivery_expected", "acks_withheld", "in_sla"}
+)
+
+// WriteCSV stores the interval time series, per-group stats and integrity breakdown of r as
+// <base>_intervals.csv, <base>_groups.csv and <base>_integrity.csv in dir and returns the paths.
+func (r Result) WriteCSV(dir string, at time.Time) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create export dir: %w", err)
+	}
+	files := []struct {
+		suffix string
+		header []string
+		rows   [][]string
+	}{
+		{"intervals", intervalColumns, r.intervalRows()},
+		{"groups", groupColumns, r.groupRows()},
+		{"integrity", integrityColumns, r.integrityRows()},
+	}
+	var paths []string
+	for _, f := range files {
+		path := filepath.Join(dir, fmt.Sprintf("%s_%s.csv", r.BaseName(at), f.suffix))
+		if err := writeCSV(path, f.header, f.rows); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+func writeCSV(path string, header []string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("write csv: %w", err)
+	}
+	w := csv.NewWriter(f)
+	_ = w.Write(header)
+	_ = w.WriteAll(rows) // flushes; errors surface through w.Error
+	if err := w.Error(); err != nil {
+		f.Close()
+		return fmt.Errorf("write csv %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func (r Result) intervalRows() [][]string {
+	var rows [][]string
+	for _, s := range r.Snapshot.Intervals {
+		l := s.LatencyMs
+		rows = append(rows, []string{r.TestName, r.RunID, s.At.UTC().Format(time.RFC3339Nano), num(s.ElapsedSec),
+			u64(s.Sent), u64(s.Received), u64(s.Errors), num(s.TxRate), num(s.RxRate), num(s.ErrorRate),
+			metrics.FormatRates(s.ErrorRates, " "),
+			strconv.Itoa(l.Count), num(l.P50), num(l.P95), num(l.P99), num(l.Max)})
+	}
+	return rows
+}
+
+// groupRows lists producer groups then consumer groups; columns that do not apply to a role are empty.
+func (r Result) groupRows() [][]string {
+	var rows [][]string
+	for _, g := range r.Snapshot.ProducerGroups {
+		rows = append(rows, []string{r.TestName, r.RunID, "producer", g.Name, strconv.Itoa(g.Workers), u64(g.Sent), "",
+			num(g.ThroughputSent), num(g.TargetRate), "", "", "", "", "", "", "", "", ""})
+	}
+	elapsed := r.Snapshot.ElapsedSec
+	for _, g := range r.Snapshot.ConsumerGroups {
+		l := g.LatencyMs
+		throughput := 0.0
+		if elapsed > 0 {
+			throughput = float64(g.Received) / elapsed
+		}
+		rows = append(rows, []string{r.TestName, r.RunID, "consumer", g.Name, strconv.Itoa(g.Workers), "", u64(g.Received),
+			num(throughput), "", num(g.Utilization), g.AckMode, u64(g.Acked), u64(g.AckFailed), u64(g.AckSkipped),
+			num(l.P50), num(l.P95), num(l.P99), num(l.Max)})
+	}
+	return rows
+}
+
+func (r Result) integrityRows() [][]string {
+	var rows [][]string
+	for _, e := range r.Snapshot.IntegrityBreakdown {
+		rows = append(rows, []string{r.TestName, r.RunID, e.Topic, e.Subscription, e.Producer, u64(e.Min), u64(e.Max),
+			u64(e.UniqueSeen), strconv.FormatBool(e.Reconciled), u64(e.Expected), u64(e.FailedSends), u64(e.Loss),
+			u64(e.Duplicates), u64(e.Redeliveries), u64(e.CrossConsumerDuplicates), u64(e.UnclassifiedDuplicates),
+			u64(e.LateArrivals), u64(e.OutOfOrder), u64(e.MaxReorderDistance), u64(e.OrderingViolations),
+			strconv.FormatBool(e.OrderingExpected), strconv.FormatBool(e.RedeliveryExpected),
+			strconv.FormatBool(e.AcksWithheld), strconv.FormatBool(e.InSLA())})
+	}
+	return rows
+}
+
+func num(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+func u64(v uint64) string { return strconv.FormatUint(v, 10) }
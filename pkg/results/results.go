@@ -0,0 +1,116 @@
+// Package results defines the exported result of a run and reads and writes it as JSON.
+package results
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/config"
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
+)
+
+// Result is the exported outcome of one run: the final snapshot plus a description of the run.
+type Result struct {
+	TestName    string               `json:"test_name"`
+	RunID       string               `json:"run_id,omitempty"`
+	Labels      map[string]string    `json:"labels,omitempty"`
+	ConfigHash  string               `json:"config_hash,omitempty"`
+	Description string               `json:"description,omitempty"`
+	ServiceURL  string               `json:"service_url"`
+	DurationSec float64              `json:"duration_sec"`
+	StartedAt   time.Time            `json:"started_at"`
+	Snapshot    metrics.Snapshot     `json:"snapshot"`
+	Thresholds  []thresholds.Verdict `json:"thresholds,omitempty"`
+	Config      ConfigSummary        `json:"config_summary"`
+	// ConfigYAML is the scenario as run, after expansion and run ID substitution
+	ConfigYAML string `json:"config_yaml,omitempty"`
+}
+
+// ConfigSummary counts the configured groups and topics.
+type ConfigSummary struct {
+	Producers int `json:"producers"`
+	Consumers int `json:"consumers"`
+	Topics    int `json:"topics"`
+}
+
+// New builds the result of a run of cfg.
+func New(cfg *config.Config, snap metrics.Snapshot, verdicts []thresholds.Verdict) Result {
+	var scenario string
+	if b, err := yaml.Marshal(cfg); err == nil {
+		scenario = string(b)
+	}
+	return Result{
+		TestName:    cfg.TestName,
+		RunID:       cfg.Execution.RunID,
+		Labels:      cfg.Labels,
+		ConfigHash:  ConfigHash(cfg),
+		Description: cfg.Description,
+		ServiceURL:  cfg.Danube.ServiceURL,
+		DurationSec: snap.ElapsedSec,
+		StartedAt:   time.Now().Add(-time.Duration(snap.ElapsedSec * float64(time.Second))),
+		Snapshot:    snap,
+		Thresholds:  verdicts,
+		Config:      ConfigSummary{Producers: len(cfg.Producers), Consumers: len(cfg.Consumers), Topics: len(cfg.Topics)},
+		ConfigYAML:  scenario,
+	}
+}
+
+// ConfigHash identifies the scenario of cfg: runs with the same hash ran the same workload.
+// The run ID and labels are left out, as they differ between runs of one scenario.
+func ConfigHash(cfg *config.Config) string {
+	c := *cfg
+	c.Execution.RunID = ""
+	c.Labels = nil
+	b, err := yaml.Marshal(&c)
+	if err != nil {
+		return ""
+	}
+	if id := cfg.Execution.RunID; id != "" {
+		b = []byte(strings.ReplaceAll(string(b), id, config.RunIDPlaceholder))
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:6])
+}
+
+// Write stores r as <test_name>_<timestamp>.json in dir, creating dir if needed, and returns the path.
+func (r Result) Write(dir string, at time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create export dir: %w", err)
+	}
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal result: %w", err)
+	}
+	path := filepath.Join(dir, r.BaseName(at)+".json")
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		return "", fmt.Errorf("write result: %w", err)
+	}
+	return path, nil
+}
+
+// BaseName returns the file name, without extension, of files written for r at the given time.
+func (r Result) BaseName(at time.Time) string {
+	return fmt.Sprintf("%s_%s", r.TestName, at.Format("20060102_150405"))
+}
+
+// Load reads a result written by Write.
+func Load(path string) (Result, error) {
+	var r Result
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return r, fmt.Errorf("read result: %w", err)
+	}
+	if err := json.Unmarshal(b, &r); err != nil {
+		return r, fmt.Errorf("parse result %s: %w", path, err)
+	}
+	return r, nil
+}
@@ -0,0 +1,138 @@
+package results
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/config"
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
+)
+
+func TestWriteAndLoad(t *testing.T) {
+	cfg := &config.Config{
+		TestName:  "soak",
+		Danube:    config.DanubeConfig{ServiceURL: "127.0.0.1:6650"},
+		Execution: config.ExecutionConfig{RunID: "r1"},
+		Topics:    []config.Topic{{Name: "/default/a"}, {Name: "/default/b"}},
+		Producers: []config.ProducerGroup{{Name: "p"}},
+	}
+	snap := metrics.Snapshot{ElapsedSec: 12.5, MessagesSent: 100, LatencyP99Ms: 4.2}
+	verdicts := []thresholds.Verdict{{Name: "p99", Metric: "latency_p99_ms", Op: "<", Limit: 20, Value: 4.2, Pass: true}}
+	r := New(cfg, snap, verdicts)
+
+	dir := filepath.Join(t.TempDir(), "results")
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	path, err := r.Write(dir, at)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if filepath.Base(path) != "soak_20250102_030405.json" {
+		t.Fatalf("unexpected file name %s", path)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.TestName != "soak" || got.RunID != "r1" || got.DurationSec != 12.5 || got.Config.Topics != 2 || got.Config.Producers != 1 {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+	if got.Snapshot.MessagesSent != 100 || got.Snapshot.LatencyP99Ms != 4.2 {
+		t.Fatalf("snapshot not preserved: %+v", got.Snapshot)
+	}
+	if len(got.Thresholds) != 1 || got.Thresholds[0] != verdicts[0] {
+		t.Fatalf("thresholds not preserved: %+v", got.Thresholds)
+	}
+	if !strings.Contains(got.ConfigYAML, "test_name: soak") || !strings.Contains(got.ConfigYAML, "run_id: r1") {
+		t.Fatalf("config not preserved:\n%s", got.ConfigYAML)
+	}
+}
+
+func TestConfigHash(t *testing.T) {
+	cfg := func(runID, topic string, labels map[string]string) *config.Config {
+		return &config.Config{
+			TestName:  "soak",
+			Labels:    labels,
+			Execution: config.ExecutionConfig{RunID: runID, Duration: "1m"},
+			Topics:    []config.Topic{{Name: topic}},
+		}
+	}
+	a := ConfigHash(cfg("r1", "/default/t_r1", map[string]string{"broker": "0.4"}))
+	b := ConfigHash(cfg("r2", "/default/t_r2", map[string]string{"broker": "0.5"}))
+	if a == "" || a != b {
+		t.Fatalf("same scenario should hash alike: %q %q", a, b)
+	}
+	c := cfg("r2", "/default/t_r2", nil)
+	c.Execution.Duration = "2m"
+	if ConfigHash(c) == a {
+		t.Fatal("a different workload should hash differently")
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	r := Result{
+		TestName: "soak",
+		RunID:    "r1",
+		Snapshot: metrics.Snapshot{
+			ElapsedSec: 10,
+			Intervals: []metrics.IntervalSample{
+				{At: at, ElapsedSec: 5, Sent: 50, TxRate: 10, LatencyMs: metrics.Distribution{Count: 3, P99: 4.5}},
+			},
+			ProducerGroups: []metrics.ProducerGroupStats{{Name: "writers", Workers: 2, Sent: 100, ThroughputSent: 10}},
+			ConsumerGroups: []metrics.ConsumerGroupStats{{Name: "readers", Workers: 1, Received: 90, AckMode: "immediate"}},
+			IntegrityBreakdown: []metrics.IntegrityEntry{
+				{Topic: "/default/a,b", Subscription: "s", Producer: "p-0", Loss: 3},
+			},
+		},
+	}
+	paths, err := r.WriteCSV(t.TempDir(), at)
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	want := []struct {
+		suffix string
+		header []string
+		rows   int
+	}{
+		{"soak_20250102_030405_intervals.csv", intervalColumns, 1},
+		{"soak_20250102_030405_groups.csv", groupColumns, 2},
+		{"soak_20250102_030405_integrity.csv", integrityColumns, 1},
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("paths got %v", paths)
+	}
+	for i, w := range want {
+		if filepath.Base(paths[i]) != w.suffix {
+			t.Fatalf("file %d got %s want %s", i, paths[i], w.suffix)
+		}
+		f, err := os.Open(paths[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		recs, err := csv.NewReader(f).ReadAll()
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", w.suffix, err)
+		}
+		if strings.Join(recs[0], ",") != strings.Join(w.header, ",") || len(recs)-1 != w.rows {
+			t.Fatalf("%s: got %v", w.suffix, recs)
+		}
+		for _, rec := range recs[1:] {
+			if len(rec) != len(w.header) || rec[0] != "soak" || rec[1] != "r1" {
+				t.Fatalf("%s: bad row %v", w.suffix, rec)
+			}
+		}
+	}
+	// a topic name with a comma stays in one column
+	f, _ := os.Open(paths[2])
+	recs, _ := csv.NewReader(f).ReadAll()
+	f.Close()
+	if recs[1][2] != "/default/a,b" || recs[1][11] != "3" || recs[1][len(recs[1])-1] != "false" {
+		t.Fatalf("integrity row %v", recs[1])
+	}
+}
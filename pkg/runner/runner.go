@@ -2,8 +2,10 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"os"
 	"sync"
 	"time"
 
@@ -11,11 +13,24 @@ import (
 	"github.com/danube-messaging/loadtest_danube/pkg/consumer"
 	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
 	"github.com/danube-messaging/loadtest_danube/pkg/producer"
+	"github.com/danube-messaging/loadtest_danube/pkg/results"
+	"github.com/danube-messaging/loadtest_danube/pkg/thresholds"
+	"github.com/danube-messaging/loadtest_danube/pkg/tui"
 	"github.com/danube-messaging/loadtest_danube/pkg/utils"
 )
 
+// Options control how a run reports, independent of the scenario.
+type Options struct {
+	// Report is the final report format: text (default), markdown or html
+	Report string
+	// JUnit, when set, is the path the JUnit XML report is written to
+	JUnit string
+	// TUI replaces the periodic stats log with a full-screen live dashboard
+	TUI bool
+}
+
 // Run executes the scenario described by cfg for the specified duration.
-func Run(cfg *config.Config) error {
+func Run(cfg *config.Config, opts Options) error {
 	// Context with interrupt and duration
 	base := context.Background()
 	ctx := utils.WithInterrupt(base)
@@ -23,21 +38,77 @@ func Run(cfg *config.Config) error {
 	if err != nil {
 		return fmt.Errorf("invalid execution.duration: %w", err)
 	}
-	ctx, cancel := context.WithTimeout(ctx, dur)
-	defer cancel()
+	// Producers stop when the duration elapses; consumers keep draining for the cooldown
+	// so that reconciliation against the sent ledger does not count in-flight messages as loss.
+	drain := time.Second
+	if cfg.Execution.CooldownDuration != "" {
+		if drain, err = time.ParseDuration(cfg.Execution.CooldownDuration); err != nil {
+			return fmt.Errorf("invalid execution.cooldown_duration: %w", err)
+		}
+	}
+	readyTimeout := 30 * time.Second
+	if cfg.Execution.ReadyTimeout != "" {
+		if readyTimeout, err = time.ParseDuration(cfg.Execution.ReadyTimeout); err != nil {
+			return fmt.Errorf("invalid execution.ready_timeout: %w", err)
+		}
+	}
+	// The run duration starts once traffic is released, not when the pools start
+	prodCtx, cancelProd := context.WithCancel(ctx)
+	defer cancelProd()
+	consCtx, cancelCons := context.WithCancel(ctx)
+	defer cancelCons()
 
 	m := metrics.NewCollector()
+	var dash *tui.Dashboard
+	if opts.TUI {
+		title := cfg.TestName
+		if cfg.Execution.RunID != "" {
+			title += " · run " + cfg.Execution.RunID
+		}
+		dash = tui.New(os.Stdout, title, time.Second)
+		dash.Start()
+		defer dash.Stop()
+	}
 
 	// Start pools
-	var wg sync.WaitGroup
+	var prodWG, consWG sync.WaitGroup
 	prodPool := producer.NewPool(cfg.Danube.ServiceURL, cfg, m)
 	consPool := consumer.NewPool(cfg.Danube.ServiceURL, cfg, m)
 
-	// Start producers first to ensure topics are created on the broker
-	prodPool.Start(ctx, &wg)
-	// Small delay to avoid races where consumers subscribe before topics exist
-	time.Sleep(1 * time.Second)
-	consPool.Start(ctx, &wg)
+	// Every producer and consumer worker reports to the barrier; producers hold their
+	// first send until it is released so late subscribers do not show up as loss.
+	participants := 0
+	for _, pg := range cfg.Producers {
+		participants += pg.Count
+	}
+	for _, cg := range cfg.Consumers {
+		participants += cg.Count
+	}
+	ready := utils.NewBarrier(participants)
+	prodPool.Start(prodCtx, &prodWG, ready)
+	consPool.Start(consCtx, &consWG, ready)
+
+	dash.SetPhase("setup", time.Now().Add(readyTimeout))
+	log.Printf("Waiting for %d producers and consumers to become ready (timeout %s)...", participants, readyTimeout)
+	res := ready.AwaitReady(ctx, readyTimeout)
+	m.RecordReadiness(metrics.ReadinessStats{
+		Expected: participants,
+		Ready:    res.Ready,
+		Failed:   res.Failed,
+		Pending:  res.Pending,
+		TimedOut: res.TimedOut,
+		WaitMs:   float64(res.Waited.Microseconds()) / 1000,
+	})
+	if res.Failed > 0 || res.TimedOut {
+		log.Printf("WARN readiness: ready=%d failed=%d pending=%d timed_out=%v; releasing traffic anyway", res.Ready, res.Failed, res.Pending, res.TimedOut)
+	}
+	// throughput is measured over the traffic phase only
+	start := time.Now()
+	m.SetStart(start)
+	ready.Release()
+	dash.SetPhase("traffic", start.Add(dur))
+	stopTraffic := time.AfterFunc(dur, cancelProd)
+	defer stopTraffic.Stop()
 
 	// periodic reporting
 	reportEvery := 5 * time.Second
@@ -49,21 +120,66 @@ func Run(cfg *config.Config) error {
 	ticker := time.NewTicker(reportEvery)
 	defer ticker.Stop()
 
-	log.Printf("Load test started for %s...", dur)
+	if cfg.Execution.Mode == "idle" {
+		log.Printf("Idle mode: holding producers and subscriptions open for %s without sending...", dur)
+	} else {
+		log.Printf("Load test started for %s...", dur)
+	}
 	for {
 		select {
-		case <-ctx.Done():
-			wg.Wait()
+		case <-prodCtx.Done():
+			// send rates end here; receive rates keep counting through the drain
+			m.EndTraffic(time.Now())
+			prodWG.Wait()
+			if ctx.Err() == nil && drain > 0 {
+				log.Printf("Producers stopped, draining consumers for %s...", drain)
+				dash.SetPhase("drain", time.Now().Add(drain))
+				select {
+				case <-time.After(drain):
+				case <-ctx.Done():
+				}
+			}
+			cancelCons()
+			consWG.Wait()
+			m.SampleInterval(time.Now())
+			// leave the dashboard before the final report is printed
+			dash.Stop()
+			m.Reconcile()
 			snap := m.Snapshot()
-			// Pretty summary and optional export delegated to helpers
-			printSummary(cfg, snap, dur)
-			exportResults(cfg, snap)
-			return nil
+			// Final report and optional export delegated to helpers
+			verdicts := thresholds.Evaluate(cfg.Thresholds, snap)
+			res := results.New(cfg, snap, verdicts)
+			printReport(opts.Report, res)
+			exportResults(cfg, res)
+			var junitErr error
+			if opts.JUnit != "" {
+				junitErr = writeJUnit(opts.JUnit, res)
+			}
+			failed := thresholds.Failed(verdicts)
+			if failed > 0 {
+				return errors.Join(fmt.Errorf("%d of %d: %w", failed, len(verdicts), thresholds.ErrFailed), junitErr)
+			}
+			return junitErr
 		case <-ticker.C:
+			sample := m.SampleInterval(time.Now())
 			snap := m.Snapshot()
-			log.Printf("Stats: elapsed=%.0fs sent=%d recv=%d err=%d tx_mps=%.1f rx_mps=%.1f lat(ms): p50=%.1f p95=%.1f p99=%.1f max=%.1f n=%d",
+			if dash != nil {
+				// the dashboard shows live stats from this interval's snapshot instead of a log line
+				dash.Update(snap)
+				continue
+			}
+			log.Printf("Stats: elapsed=%.0fs sent=%d recv=%d err=%d tx_mps=%.1f rx_mps=%.1f lat(ms): p50=%.1f p95=%.1f p99=%.1f max=%.1f n=%d%s",
 				snap.ElapsedSec, snap.MessagesSent, snap.MessagesReceived, snap.Errors, snap.ThroughputSent, snap.ThroughputRecv,
-				snap.LatencyP50Ms, snap.LatencyP95Ms, snap.LatencyP99Ms, snap.LatencyMaxMs, snap.LatencySamples)
+				snap.LatencyP50Ms, snap.LatencyP95Ms, snap.LatencyP99Ms, snap.LatencyMaxMs, snap.LatencySamples,
+				errorRates(sample.ErrorRates))
 		}
 	}
 }
+
+// errorRates formats an interval's per-category error rates, e.g. " err/s: ack=0.4 send=1.2".
+func errorRates(rates map[string]float64) string {
+	if len(rates) == 0 {
+		return ""
+	}
+	return " err/s: " + metrics.FormatRates(rates, " ")
+}
@@ -0,0 +1,283 @@
+// Package thresholds evaluates pass/fail assertions against the final results of a run.
+package thresholds
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/config"
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+)
+
+// ErrFailed is returned by a run whose thresholds were breached.
+var ErrFailed = errors.New("thresholds failed")
+
+// Verdict is the outcome of one threshold.
+type Verdict struct {
+	Name   string  `json:"name"`
+	Metric string  `json:"metric"`
+	Scope  string  `json:"scope,omitempty"`
+	Op     string  `json:"op"`
+	Limit  float64 `json:"limit"`
+	Value  float64 `json:"value"`
+	Pass   bool    `json:"pass"`
+	// Error explains why the metric could not be measured; such a threshold fails
+	Error string `json:"error,omitempty"`
+}
+
+// Evaluate checks every threshold against the snapshot, in config order.
+func Evaluate(ths []config.Threshold, snap metrics.Snapshot) []Verdict {
+	out := make([]Verdict, 0, len(ths))
+	for _, th := range ths {
+		v := Verdict{Name: th.Name, Metric: th.Metric, Scope: scope(th), Op: th.Op, Limit: th.Value}
+		if v.Name == "" {
+			v.Name = strings.TrimSpace(fmt.Sprintf("%s %s %g %s", th.Metric, th.Op, th.Value, v.Scope))
+		}
+		value, err := measure(th, snap)
+		if err != nil {
+			v.Error = err.Error()
+		} else {
+			v.Value = value
+			v.Pass = compare(value, th.Op, th.Value)
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// Failed returns the number of verdicts that did not pass.
+func Failed(vs []Verdict) int {
+	n := 0
+	for _, v := range vs {
+		if !v.Pass {
+			n++
+		}
+	}
+	return n
+}
+
+func scope(th config.Threshold) string {
+	var parts []string
+	if th.Role != "" {
+		parts = append(parts, "role="+th.Role)
+	}
+	if th.Group != "" {
+		parts = append(parts, "group="+th.Group)
+	}
+	if th.Topic != "" {
+		parts = append(parts, "topic="+th.Topic)
+	}
+	if th.ReliableOnly {
+		parts = append(parts, "reliable topics")
+	}
+	return strings.Join(parts, " ")
+}
+
+func compare(v float64, op string, limit float64) bool {
+	switch op {
+	case "<":
+		return v < limit
+	case "<=":
+		return v <= limit
+	case ">":
+		return v > limit
+	case ">=":
+		return v >= limit
+	case "==":
+		return v == limit
+	case "!=":
+		return v != limit
+	}
+	return false
+}
+
+func measure(th config.Threshold, snap metrics.Snapshot) (float64, error) {
+	switch th.Metric {
+	case "latency_p50_ms", "latency_p95_ms", "latency_p99_ms", "latency_max_ms":
+		return latency(th, snap)
+	case "throughput_sent":
+		if th.Group == "" {
+			return snap.ThroughputSent, nil
+		}
+		pg, err := producerGroup(snap, th.Group)
+		if err != nil {
+			return 0, err
+		}
+		return pg.ThroughputSent, nil
+	case "throughput_recv":
+		if th.Group == "" {
+			return snap.ThroughputRecv, nil
+		}
+		cg, err := consumerGroup(snap, th.Group)
+		if err != nil {
+			return 0, err
+		}
+		if snap.ElapsedSec <= 0 {
+			return 0, nil
+		}
+		return float64(cg.Received) / snap.ElapsedSec, nil
+	case "throughput_percent_of_target":
+		return percentOfTarget(th, snap)
+	case "errors":
+		n, err := errorsIn(th, snap)
+		return float64(n), err
+	case "error_rate_percent":
+		// errors per message sent, or received for consumer errors
+		errs, err := errorsIn(th, snap)
+		if err != nil {
+			return 0, err
+		}
+		sent, err := messagesIn(th, snap)
+		if err != nil {
+			return 0, err
+		}
+		if sent == 0 {
+			if errs > 0 {
+				return 100, nil
+			}
+			return 0, nil
+		}
+		return float64(errs) * 100 / float64(sent), nil
+	case "loss", "duplicates", "unexpected_duplicates", "ordering_violations":
+		return integrity(th, snap)
+	}
+	return 0, fmt.Errorf("unsupported metric %q", th.Metric)
+}
+
+func latency(th config.Threshold, snap metrics.Snapshot) (float64, error) {
+	d := metrics.Distribution{Count: snap.LatencySamples, P50: snap.LatencyP50Ms, P95: snap.LatencyP95Ms, P99: snap.LatencyP99Ms, Max: snap.LatencyMaxMs}
+	if th.Group != "" {
+		cg, err := consumerGroup(snap, th.Group)
+		if err != nil {
+			return 0, err
+		}
+		d = cg.LatencyMs
+	}
+	// no samples must not pass a "less than" assertion by reading as zero
+	if d.Count == 0 {
+		return 0, fmt.Errorf("no latency samples")
+	}
+	switch th.Metric {
+	case "latency_p50_ms":
+		return d.P50, nil
+	case "latency_p95_ms":
+		return d.P95, nil
+	case "latency_p99_ms":
+		return d.P99, nil
+	}
+	return d.Max, nil
+}
+
+func percentOfTarget(th config.Threshold, snap metrics.Snapshot) (float64, error) {
+	var achieved, target float64
+	for _, pg := range snap.ProducerGroups {
+		if th.Group != "" && pg.Name != th.Group {
+			continue
+		}
+		if pg.TargetRate == 0 {
+			if th.Group != "" {
+				return 0, fmt.Errorf("producer group %s has no target rate", th.Group)
+			}
+			continue // unlimited groups have no target to measure against
+		}
+		achieved += pg.ThroughputSent
+		target += pg.TargetRate
+	}
+	if target == 0 {
+		if th.Group != "" {
+			return 0, fmt.Errorf("producer group %s not found", th.Group)
+		}
+		return 0, fmt.Errorf("no producer group has a target rate")
+	}
+	return achieved * 100 / target, nil
+}
+
+// errorsIn counts the errors of th's role and group. A group must exist in that role,
+// or in either role when th has none.
+func errorsIn(th config.Threshold, snap metrics.Snapshot) (uint64, error) {
+	if th.Group == "" && th.Role == "" {
+		return snap.Errors, nil
+	}
+	if th.Group != "" {
+		if _, err := messagesIn(th, snap); err != nil {
+			return 0, err
+		}
+	}
+	var n uint64
+	for _, e := range snap.ErrorBreakdown {
+		if (th.Group == "" || e.Group == th.Group) && (th.Role == "" || e.Role == th.Role) {
+			n += e.Count
+		}
+	}
+	return n, nil
+}
+
+// messagesIn returns the messages an error rate is measured against: those sent by th's
+// producer group, or received by its consumer group. Without a group the run totals are used.
+func messagesIn(th config.Threshold, snap metrics.Snapshot) (uint64, error) {
+	if th.Group == "" {
+		if th.Role == metrics.SetupConsumer {
+			return snap.MessagesReceived, nil
+		}
+		return snap.MessagesSent, nil
+	}
+	if th.Role != metrics.SetupConsumer {
+		if pg, err := producerGroup(snap, th.Group); err == nil || th.Role == metrics.SetupProducer {
+			return pg.Sent, err
+		}
+	}
+	cg, err := consumerGroup(snap, th.Group)
+	if err != nil && th.Role == "" {
+		return 0, fmt.Errorf("group %s not found", th.Group)
+	}
+	return cg.Received, err
+}
+
+// integrity sums th's integrity metric over the keys its topic and reliable_only filters match.
+// A filter matching no key is an error, so it can't pass without checking anything.
+func integrity(th config.Threshold, snap metrics.Snapshot) (float64, error) {
+	var n uint64
+	matched := 0
+	for _, e := range snap.IntegrityBreakdown {
+		if th.Topic != "" && e.Topic != th.Topic {
+			continue
+		}
+		if th.ReliableOnly && !e.RedeliveryExpected {
+			continue
+		}
+		matched++
+		switch th.Metric {
+		case "loss":
+			n += e.Loss
+		case "duplicates":
+			n += e.Duplicates
+		case "unexpected_duplicates":
+			n += e.UnexpectedDuplicates()
+		case "ordering_violations":
+			n += e.OrderingViolations
+		}
+	}
+	if matched == 0 && (th.Topic != "" || th.ReliableOnly) {
+		return 0, fmt.Errorf("no integrity keys for %s", scope(th))
+	}
+	return float64(n), nil
+}
+
+func producerGroup(snap metrics.Snapshot, name string) (metrics.ProducerGroupStats, error) {
+	for _, pg := range snap.ProducerGroups {
+		if pg.Name == name {
+			return pg, nil
+		}
+	}
+	return metrics.ProducerGroupStats{}, fmt.Errorf("producer group %s not found", name)
+}
+
+func consumerGroup(snap metrics.Snapshot, name string) (metrics.ConsumerGroupStats, error) {
+	for _, cg := range snap.ConsumerGroups {
+		if cg.Name == name {
+			return cg, nil
+		}
+	}
+	return metrics.ConsumerGroupStats{}, fmt.Errorf("consumer group %s not found", name)
+}
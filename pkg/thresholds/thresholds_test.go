@@ -0,0 +1,101 @@
+package thresholds
+
+import (
+	"testing"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/config"
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+)
+
+func testSnapshot() metrics.Snapshot {
+	return metrics.Snapshot{
+		ElapsedSec:     10,
+		MessagesSent:   10000,
+		Errors:         5,
+		ThroughputSent: 1000,
+		LatencySamples: 100,
+		LatencyP99Ms:   12,
+		ConsumerGroups: []metrics.ConsumerGroupStats{
+			{Name: "fast", Received: 5000, LatencyMs: metrics.Distribution{Count: 50, P99: 8}},
+			{Name: "slow", Received: 5000, LatencyMs: metrics.Distribution{Count: 50, P99: 35}},
+			{Name: "idle"},
+		},
+		ProducerGroups: []metrics.ProducerGroupStats{
+			{Name: "pubs", Sent: 9600, ThroughputSent: 960, TargetRate: 1000},
+			{Name: "burst", Sent: 400, ThroughputSent: 40},
+		},
+		ErrorBreakdown: []metrics.ErrorCount{
+			{Category: metrics.ErrSend, Role: metrics.SetupProducer, Group: "pubs", Code: "Unavailable", Count: 5},
+		},
+		IntegrityBreakdown: []metrics.IntegrityEntry{
+			{Topic: "/default/reliable", RedeliveryExpected: true, Loss: 0, Duplicates: 3, Redeliveries: 3},
+			{Topic: "/default/plain", Loss: 7, Duplicates: 2},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := []struct {
+		th    config.Threshold
+		value float64
+		pass  bool
+	}{
+		{config.Threshold{Metric: "latency_p99_ms", Op: "<", Value: 20}, 12, true},
+		{config.Threshold{Metric: "latency_p99_ms", Op: "<", Value: 20, Group: "fast"}, 8, true},
+		{config.Threshold{Metric: "latency_p99_ms", Op: "<", Value: 20, Group: "slow"}, 35, false},
+		{config.Threshold{Metric: "loss", Op: "==", Value: 0, ReliableOnly: true}, 0, true},
+		{config.Threshold{Metric: "loss", Op: "==", Value: 0}, 7, false},
+		{config.Threshold{Metric: "loss", Op: "<=", Value: 7, Topic: "/default/plain"}, 7, true},
+		{config.Threshold{Metric: "unexpected_duplicates", Op: "==", Value: 0}, 2, false},
+		{config.Threshold{Metric: "error_rate_percent", Op: "<", Value: 0.1}, 0.05, true},
+		{config.Threshold{Metric: "errors", Op: "==", Value: 5, Group: "pubs"}, 5, true},
+		{config.Threshold{Metric: "errors", Op: "==", Value: 0, Role: "consumer"}, 0, true},
+		{config.Threshold{Metric: "error_rate_percent", Op: "<", Value: 1, Group: "pubs", Role: "producer"}, 5.0 * 100 / 9600, true},
+		{config.Threshold{Metric: "throughput_percent_of_target", Op: ">=", Value: 95}, 96, true},
+		{config.Threshold{Metric: "throughput_percent_of_target", Op: ">=", Value: 97, Group: "pubs"}, 96, false},
+		{config.Threshold{Metric: "throughput_recv", Op: ">", Value: 400, Group: "fast"}, 500, true},
+	}
+	for _, tc := range cases {
+		v := Evaluate([]config.Threshold{tc.th}, testSnapshot())[0]
+		if v.Error != "" {
+			t.Errorf("%s: unexpected error %s", v.Name, v.Error)
+			continue
+		}
+		if v.Value != tc.value || v.Pass != tc.pass {
+			t.Errorf("%s: got value=%v pass=%v want value=%v pass=%v", v.Name, v.Value, v.Pass, tc.value, tc.pass)
+		}
+	}
+}
+
+func TestEvaluateUnmeasurableFails(t *testing.T) {
+	ths := []config.Threshold{
+		{Metric: "latency_p99_ms", Op: "<", Value: 20, Group: "missing"},
+		// a group without samples must not pass by reading as zero
+		{Metric: "latency_p99_ms", Op: "<", Value: 20, Group: "idle"},
+		{Metric: "throughput_percent_of_target", Op: ">=", Value: 95, Group: "burst"},
+		// filters and groups that match nothing must not pass by checking nothing
+		{Metric: "loss", Op: "==", Value: 0, Topic: "/default/missing"},
+		{Metric: "error_rate_percent", Op: "<", Value: 1, Group: "missing"},
+		{Metric: "errors", Op: "==", Value: 0, Group: "pubs", Role: "consumer"},
+	}
+	vs := Evaluate(ths, testSnapshot())
+	if Failed(vs) != len(ths) {
+		t.Fatalf("expected every threshold to fail, got %+v", vs)
+	}
+	for _, v := range vs {
+		if v.Error == "" {
+			t.Errorf("%s: expected an explanation", v.Name)
+		}
+	}
+}
+
+func TestVerdictName(t *testing.T) {
+	v := Evaluate([]config.Threshold{{Metric: "latency_p99_ms", Op: "<", Value: 20, Group: "fast"}}, testSnapshot())[0]
+	if v.Name != "latency_p99_ms < 20 group=fast" {
+		t.Fatalf("default name got %q", v.Name)
+	}
+	v = Evaluate([]config.Threshold{{Name: "p99 budget", Metric: "latency_p99_ms", Op: "<", Value: 20}}, testSnapshot())[0]
+	if v.Name != "p99 budget" {
+		t.Fatalf("configured name got %q", v.Name)
+	}
+}
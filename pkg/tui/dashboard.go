@@ -0,0 +1,286 @@
+// Package tui draws a full-screen live dashboard of a run with plain ANSI escape codes.
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+	"github.com/danube-messaging/loadtest_danube/pkg/utils"
+)
+
+// ANSI sequences used by the dashboard.
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	home         = "\x1b[H"
+	clearToEnd   = "\x1b[J"
+	clearLine    = "\x1b[K"
+	bold         = "\x1b[1m"
+	red          = "\x1b[31m"
+	green        = "\x1b[32m"
+	dim          = "\x1b[2m"
+	reset        = "\x1b[0m"
+)
+
+const (
+	// sparkPoints is how many recent intervals the sparklines show
+	sparkPoints = 60
+	// logLines is how many recent log lines the error panel keeps
+	logLines = 10
+)
+
+// Dashboard redraws the live state of a run on a terminal. While it runs, the standard
+// logger writes into its log panel instead of the screen. Stats come from the snapshot
+// passed to Update once per reporting interval; redraws in between only refresh the
+// countdown and the log panel, so a redraw never takes a snapshot itself. A nil *Dashboard
+// ignores Update, SetPhase and Stop, so callers need not check whether it is enabled.
+type Dashboard struct {
+	out   io.Writer
+	title string
+	every time.Duration
+
+	mu       sync.Mutex
+	snap     metrics.Snapshot
+	phase    string
+	phaseEnd time.Time
+	logs     []string
+	partial  []byte
+
+	stopOnce sync.Once
+	done     chan struct{}
+	stopped  chan struct{}
+	logOut   io.Writer
+}
+
+// New returns a dashboard titled title that redraws every interval on out.
+func New(out io.Writer, title string, every time.Duration) *Dashboard {
+	return &Dashboard{out: out, title: title, every: every, done: make(chan struct{}), stopped: make(chan struct{})}
+}
+
+// Update replaces the snapshot the dashboard shows from the next redraw on.
+func (d *Dashboard) Update(snap metrics.Snapshot) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.snap = snap
+	d.mu.Unlock()
+}
+
+// Start switches to the alternate screen, captures the standard logger and starts redrawing.
+func (d *Dashboard) Start() {
+	d.logOut = log.Writer()
+	log.SetOutput(d)
+	fmt.Fprint(d.out, altScreenOn, hideCursor)
+	go func() {
+		defer close(d.stopped)
+		t := time.NewTicker(d.every)
+		defer t.Stop()
+		for {
+			d.draw()
+			select {
+			case <-t.C:
+			case <-d.done:
+				return
+			}
+		}
+	}()
+}
+
+// Stop restores the screen and the standard logger. It is safe to call more than once.
+func (d *Dashboard) Stop() {
+	if d == nil {
+		return
+	}
+	d.stopOnce.Do(func() {
+		close(d.done)
+		<-d.stopped
+		fmt.Fprint(d.out, showCursor, altScreenOff)
+		log.SetOutput(d.logOut)
+	})
+}
+
+// SetPhase names the current phase of the run and when it ends, for the countdown.
+func (d *Dashboard) SetPhase(name string, end time.Time) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.phase, d.phaseEnd = name, end
+	d.mu.Unlock()
+}
+
+// Write collects log output for the log panel, keeping the last logLines lines.
+func (d *Dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.partial = append(d.partial, p...)
+	for {
+		i := bytes.IndexByte(d.partial, '\n')
+		if i < 0 {
+			break
+		}
+		d.logs = append(d.logs, string(d.partial[:i]))
+		d.partial = d.partial[i+1:]
+	}
+	if n := len(d.logs); n > logLines {
+		d.logs = append(d.logs[:0], d.logs[n-logLines:]...)
+	}
+	return len(p), nil
+}
+
+func (d *Dashboard) draw() {
+	d.mu.Lock()
+	snap := d.snap
+	st := state{Title: d.title, Phase: d.phase, PhaseEnd: d.phaseEnd, Logs: append([]string(nil), d.logs...)}
+	d.mu.Unlock()
+	fmt.Fprint(d.out, home, strings.ReplaceAll(frame(st, snap, time.Now(), d.width()), "\n", clearLine+"\n"), clearToEnd)
+}
+
+// state is what the dashboard knows besides the snapshot.
+type state struct {
+	Title    string
+	Phase    string
+	PhaseEnd time.Time
+	Logs     []string
+}
+
+// frame renders one screen of the dashboard, cols characters wide.
+func frame(st state, snap metrics.Snapshot, now time.Time, cols int) string {
+	var b strings.Builder
+	left := ""
+	if !st.PhaseEnd.IsZero() {
+		left = formatRemaining(st.PhaseEnd.Sub(now)) + " left"
+	}
+	header := fmt.Sprintf(" loadtest · %s", st.Title)
+	status := fmt.Sprintf("%s  %s ", strings.ToUpper(st.Phase), left)
+	pad := cols - len([]rune(header)) - len([]rune(status))
+	if pad < 1 {
+		pad = 1
+	}
+	fmt.Fprintf(&b, "%s%s%s%s%s\n", bold, header, strings.Repeat(" ", pad), status, reset)
+	b.WriteString(strings.Repeat("─", cols) + "\n")
+
+	// rates and latency: current interval values with a sparkline of recent intervals
+	var tx, rx, p99 []float64
+	iv := snap.Intervals
+	if len(iv) > sparkPoints {
+		iv = iv[len(iv)-sparkPoints:]
+	}
+	for _, s := range iv {
+		tx = append(tx, s.TxRate)
+		rx = append(rx, s.RxRate)
+		p99 = append(p99, s.LatencyMs.P99)
+	}
+	last := func(v []float64) float64 {
+		if len(v) == 0 {
+			return 0
+		}
+		return v[len(v)-1]
+	}
+	fmt.Fprintf(&b, " tx  %10.1f msg/s  %s\n", last(tx), utils.Sparkline(tx))
+	fmt.Fprintf(&b, " rx  %10.1f msg/s  %s\n", last(rx), utils.Sparkline(rx))
+	fmt.Fprintf(&b, " p99 %10.1f ms     %s\n", last(p99), utils.Sparkline(p99))
+	fmt.Fprintf(&b, " total  sent=%d  recv=%d  errors=%d  elapsed=%.0fs  avg tx=%.1f rx=%.1f msg/s\n\n",
+		snap.MessagesSent, snap.MessagesReceived, snap.Errors, snap.ElapsedSec, snap.ThroughputSent, snap.ThroughputRecv)
+
+	// integrity counters, red when something is wrong
+	integrity := fmt.Sprintf("loss=%d  duplicates=%d  out_of_order=%d  violations=%d  stale=%d",
+		snap.EstimatedLoss, snap.Duplicates, snap.OutOfOrder, snap.OrderingViolations, snap.StaleMessages)
+	color := green
+	if snap.EstimatedLoss > 0 || snap.OrderingViolations > 0 {
+		color = red
+	}
+	fmt.Fprintf(&b, " %sIntegrity%s  %s%s%s\n\n", bold, reset, color, integrity, reset)
+
+	// per-group rows
+	if len(snap.ProducerGroups) > 0 || len(snap.ConsumerGroups) > 0 {
+		tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, " GROUP\tROLE\tWORKERS\tMESSAGES\tMSG/S\tP99 MS\tUTILIZATION\t")
+		for _, g := range snap.ProducerGroups {
+			fmt.Fprintf(tw, " %s\tproducer\t%d\t%d\t%.1f\t-\t-\t\n", g.Name, g.Workers, g.Sent, g.ThroughputSent)
+		}
+		for _, g := range snap.ConsumerGroups {
+			perSec := 0.0
+			if snap.ElapsedSec > 0 {
+				perSec = float64(g.Received) / snap.ElapsedSec
+			}
+			fmt.Fprintf(tw, " %s\tconsumer\t%d\t%d\t%.1f\t%.1f\t%.0f%%\t\n", g.Name, g.Workers, g.Received, perSec, g.LatencyMs.P99, g.Utilization*100)
+		}
+		tw.Flush()
+		b.WriteString("\n")
+	}
+
+	// errors by category, then the most recent log lines
+	var rates map[string]float64
+	if n := len(snap.Intervals); n > 0 {
+		rates = snap.Intervals[n-1].ErrorRates
+	}
+	fmt.Fprintf(&b, " %sErrors%s %s\n", bold, reset, errorCategories(snap.ErrorCategories, rates))
+	for _, l := range st.Logs {
+		if r := []rune(l); len(r) > cols-2 {
+			l = string(r[:cols-3]) + "…"
+		}
+		fmt.Fprintf(&b, " %s%s%s\n", dim, l, reset)
+	}
+	return b.String()
+}
+
+// errorCategories lists error totals per category, with the rate over the last interval
+// for categories that are still failing.
+func errorCategories(cats map[string]uint64, rates map[string]float64) string {
+	if len(cats) == 0 {
+		return "none"
+	}
+	names := make([]string, 0, len(cats))
+	for c := range cats {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, c := range names {
+		parts[i] = fmt.Sprintf("%s%s=%d%s", red, c, cats[c], reset)
+		if r, ok := rates[c]; ok {
+			parts[i] += fmt.Sprintf(" (%.1f/s)", r)
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatRemaining formats a countdown as mm:ss, or h:mm:ss from an hour up.
+func formatRemaining(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	s := int(d.Round(time.Second).Seconds())
+	if s >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", s/3600, s/60%60, s%60)
+	}
+	return fmt.Sprintf("%02d:%02d", s/60, s%60)
+}
+
+// width returns the width of the terminal the dashboard draws on. When out is not a terminal
+// it falls back to $COLUMNS, which shells rarely export, and then to 100.
+func (d *Dashboard) width() int {
+	if f, ok := d.out.(*os.File); ok {
+		if n, ok := terminalWidth(f.Fd()); ok && n > 20 {
+			return n
+		}
+	}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 20 {
+		return n
+	}
+	return 100
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/metrics"
+)
+
+func TestFrame(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	snap := metrics.Snapshot{
+		MessagesSent:    100,
+		EstimatedLoss:   2,
+		ErrorCategories: map[string]uint64{"send": 3, "ack": 1},
+		Intervals: []metrics.IntervalSample{
+			{TxRate: 10, RxRate: 9, LatencyMs: metrics.Distribution{P99: 4}},
+			{TxRate: 20, RxRate: 19, LatencyMs: metrics.Distribution{P99: 8}, ErrorRates: map[string]float64{"send": 1.5}},
+		},
+		ProducerGroups: []metrics.ProducerGroupStats{{Name: "writers", Workers: 2, Sent: 100, ThroughputSent: 20}},
+		ConsumerGroups: []metrics.ConsumerGroupStats{{Name: "readers", Workers: 1, Received: 90}},
+	}
+	st := state{Title: "soak", Phase: "traffic", PhaseEnd: now.Add(83 * time.Second), Logs: []string{"send error topic=/default/t"}}
+	out := frame(st, snap, now, 80)
+	for _, want := range []string{
+		"loadtest · soak", "TRAFFIC  01:23 left", "20.0 msg/s  ▁█", "8.0 ms     ▁█",
+		"loss=2", "writers", "readers", "ack=1", "send=3" + reset + " (1.5/s)", "send error topic=/default/t",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("frame misses %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "ack=1") > strings.Index(out, "send=3") {
+		t.Error("error categories should be sorted")
+	}
+}
+
+func TestWriteKeepsLastLogLines(t *testing.T) {
+	d := New(&bytes.Buffer{}, "t", time.Second)
+	for i := 0; i < logLines+5; i++ {
+		fmt.Fprintf(d, "line %d\n", i)
+	}
+	d.Write([]byte("partial"))
+	if len(d.logs) != logLines || d.logs[0] != "line 5" || d.logs[logLines-1] != fmt.Sprintf("line %d", logLines+4) {
+		t.Fatalf("logs %v", d.logs)
+	}
+	d.Write([]byte(" done\n"))
+	if got := d.logs[logLines-1]; got != "partial done" {
+		t.Fatalf("joined line got %q", got)
+	}
+}
+
+func TestStartStopRestoresLogger(t *testing.T) {
+	var screen, logs bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	defer log.SetOutput(prev)
+
+	d := New(&screen, "t", time.Hour)
+	d.Update(metrics.Snapshot{MessagesSent: 42})
+	d.Start()
+	log.Print("captured")
+	d.Stop()
+	d.Stop()
+	log.Print("restored")
+
+	if !strings.HasPrefix(screen.String(), altScreenOn) || !strings.HasSuffix(screen.String(), altScreenOff) {
+		t.Fatalf("screen not switched and restored: %q", screen.String())
+	}
+	if strings.Contains(logs.String(), "captured") || !strings.Contains(logs.String(), "restored") {
+		t.Fatalf("logger not captured and restored: %q", logs.String())
+	}
+	if !strings.Contains(screen.String(), "sent=42") {
+		t.Fatalf("the first redraw should show the updated snapshot: %q", screen.String())
+	}
+	var nilDash *Dashboard
+	nilDash.Update(metrics.Snapshot{})
+	nilDash.SetPhase("x", time.Now())
+	nilDash.Stop()
+}
+
+func TestFormatRemaining(t *testing.T) {
+	for d, want := range map[time.Duration]string{-time.Second: "00:00", 83 * time.Second: "01:23", 3723 * time.Second: "1:02:03"} {
+		if got := formatRemaining(d); got != want {
+			t.Errorf("%s got %s want %s", d, got, want)
+		}
+	}
+}
+
+func TestWidthFallsBackWithoutTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	t.Setenv("COLUMNS", "")
+	if w := New(f, "t", time.Second).width(); w != 100 {
+		t.Fatalf("width of a plain file got %d want the default 100", w)
+	}
+	t.Setenv("COLUMNS", "132")
+	if w := New(&bytes.Buffer{}, "t", time.Second).width(); w != 132 {
+		t.Fatalf("width without a terminal got %d want $COLUMNS", w)
+	}
+}
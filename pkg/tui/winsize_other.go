@@ -0,0 +1,8 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd)
+
+package tui
+
+// terminalWidth is not supported on this platform; the dashboard falls back to $COLUMNS.
+func terminalWidth(fd uintptr) (int, bool) {
+	return 0, false
+}
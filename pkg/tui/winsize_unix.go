@@ -0,0 +1,17 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd
+
+package tui
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// terminalWidth returns the column count of the terminal open on fd.
+func terminalWidth(fd uintptr) (int, bool) {
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws))); errno != 0 {
+		return 0, false
+	}
+	return int(ws.Col), ws.Col > 0
+}
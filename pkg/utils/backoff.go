@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/danube-messaging/loadtest_danube/pkg/config"
+)
+
+// Backoff yields exponentially growing delays between Initial and Max.
+// The zero value uses 100ms, 10s and a multiplier of 2.
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+
+	next time.Duration
+}
+
+// NewBackoff returns the backoff described by a validated reconnect config; nil yields the defaults.
+func NewBackoff(rc *config.ReconnectConfig) Backoff {
+	var b Backoff
+	if rc == nil {
+		return b
+	}
+	b.Initial, _ = time.ParseDuration(rc.InitialBackoff)
+	b.Max, _ = time.ParseDuration(rc.MaxBackoff)
+	b.Multiplier = rc.Multiplier
+	return b
+}
+
+// Next returns the delay before the next attempt and grows the following one.
+func (b *Backoff) Next() time.Duration {
+	if b.Initial <= 0 {
+		b.Initial = 100 * time.Millisecond
+	}
+	if b.Max <= 0 {
+		b.Max = 10 * time.Second
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = 2
+	}
+	if b.next == 0 {
+		b.next = b.Initial
+	}
+	d := b.next
+	if grown := time.Duration(float64(b.next) * b.Multiplier); grown < b.Max {
+		b.next = grown
+	} else {
+		b.next = b.Max
+	}
+	return min(d, b.Max)
+}
+
+// Reset starts the sequence over from Initial, after a successful attempt.
+func (b *Backoff) Reset() { b.next = 0 }
+
+// Sleep waits for d or until ctx ends; it reports whether the full delay elapsed.
+func Sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBackoffGrowsAndCaps(t *testing.T) {
+	b := Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond, Multiplier: 2}
+	want := []time.Duration{10, 20, 40, 50, 50}
+	for i, w := range want {
+		if got := b.Next(); got != w*time.Millisecond {
+			t.Fatalf("step %d got %v want %v", i, got, w*time.Millisecond)
+		}
+	}
+	b.Reset()
+	if got := b.Next(); got != 10*time.Millisecond {
+		t.Fatalf("after reset got %v want 10ms", got)
+	}
+}
+
+func TestBackoffDefaults(t *testing.T) {
+	var b Backoff
+	if got := b.Next(); got != 100*time.Millisecond {
+		t.Fatalf("default initial got %v", got)
+	}
+	if got := b.Next(); got != 200*time.Millisecond {
+		t.Fatalf("default multiplier got %v", got)
+	}
+}
+
+func TestSleepStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if Sleep(ctx, time.Hour) {
+		t.Fatal("Sleep should return false on a cancelled context")
+	}
+	if !Sleep(context.Background(), time.Millisecond) {
+		t.Fatal("Sleep should return true after the delay")
+	}
+}
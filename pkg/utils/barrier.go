@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Barrier holds traffic until a known number of participants have each reported Ready or
+// Fail, then releases them all at once. A nil *Barrier never blocks.
+type Barrier struct {
+	mu      sync.Mutex
+	pending int
+	ready   int
+	failed  int
+	done    chan struct{} // closed once every participant has reported
+	release chan struct{}
+	once    sync.Once
+}
+
+// BarrierResult is the state of a barrier when AwaitReady returned.
+type BarrierResult struct {
+	Ready    int
+	Failed   int
+	Pending  int // participants that had not reported when the wait ended
+	TimedOut bool
+	Waited   time.Duration
+}
+
+// NewBarrier returns a barrier expecting the given number of participants.
+func NewBarrier(participants int) *Barrier {
+	b := &Barrier{pending: participants, done: make(chan struct{}), release: make(chan struct{})}
+	if participants <= 0 {
+		close(b.done)
+	}
+	return b
+}
+
+// Ready reports that one participant is ready for traffic.
+func (b *Barrier) Ready() { b.report(true) }
+
+// Fail reports that one participant gave up before becoming ready.
+func (b *Barrier) Fail() { b.report(false) }
+
+func (b *Barrier) report(ok bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pending == 0 {
+		return
+	}
+	if ok {
+		b.ready++
+	} else {
+		b.failed++
+	}
+	b.pending--
+	if b.pending == 0 {
+		close(b.done)
+	}
+}
+
+// Wait blocks until the barrier is released; it returns false if ctx ended first.
+func (b *Barrier) Wait(ctx context.Context) bool {
+	if b == nil {
+		return true
+	}
+	select {
+	case <-b.release:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// AwaitReady waits until every participant has reported, the timeout elapses or ctx ends.
+// It does not release the barrier.
+func (b *Barrier) AwaitReady(ctx context.Context, timeout time.Duration) BarrierResult {
+	start := time.Now()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	var timedOut bool
+	select {
+	case <-b.done:
+	case <-t.C:
+		timedOut = true
+	case <-ctx.Done():
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return BarrierResult{Ready: b.ready, Failed: b.failed, Pending: b.pending, TimedOut: timedOut, Waited: time.Since(start)}
+}
+
+// Release lets every waiting participant proceed. It is safe to call more than once.
+func (b *Barrier) Release() {
+	if b == nil {
+		return
+	}
+	b.once.Do(func() { close(b.release) })
+}
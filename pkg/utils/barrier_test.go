@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBarrierAllReady(t *testing.T) {
+	b := NewBarrier(3)
+	b.Ready()
+	b.Ready()
+	b.Fail()
+	res := b.AwaitReady(context.Background(), time.Second)
+	if res.TimedOut || res.Ready != 2 || res.Failed != 1 || res.Pending != 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestBarrierTimeout(t *testing.T) {
+	b := NewBarrier(2)
+	b.Ready()
+	res := b.AwaitReady(context.Background(), 20*time.Millisecond)
+	if !res.TimedOut || res.Ready != 1 || res.Pending != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	// a participant reporting after the timeout must not panic
+	b.Ready()
+	b.Ready()
+}
+
+func TestBarrierHoldsUntilRelease(t *testing.T) {
+	b := NewBarrier(1)
+	released := make(chan bool)
+	go func() { released <- b.Wait(context.Background()) }()
+	b.Ready()
+	select {
+	case <-released:
+		t.Fatal("Wait returned before Release")
+	case <-time.After(20 * time.Millisecond):
+	}
+	b.Release()
+	b.Release()
+	if ok := <-released; !ok {
+		t.Fatal("Wait should report release")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if NewBarrier(1).Wait(ctx) {
+		t.Fatal("Wait should give up when ctx ends")
+	}
+	var nilBarrier *Barrier
+	nilBarrier.Ready()
+	if !nilBarrier.Wait(ctx) {
+		t.Fatal("nil barrier must not block")
+	}
+}
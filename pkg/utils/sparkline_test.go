@@ -0,0 +1,12 @@
+package utils
+
+import "testing"
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]float64{1, 2, 3, 4, 5, 6, 7, 8}); got != "▁▂▃▄▅▆▇█" {
+		t.Fatalf("got %q", got)
+	}
+	if got := Sparkline([]float64{3, 3}); got != "▁▁" {
+		t.Fatalf("flat got %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+package workload
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AckSpec describes how a consumer acknowledges received messages.
+type AckSpec struct {
+	Mode        string        // immediate|delayed|every_n|never|drop (default immediate)
+	Delay       time.Duration // delayed
+	Every       int           // every_n: ack only every Nth message
+	DropPercent float64       // drop: percentage of messages left unacked
+}
+
+// AckDecision tells the consumer what to do with one message.
+type AckDecision struct {
+	Ack   bool
+	Delay time.Duration // wait this long before acking (only when Ack is set)
+}
+
+// AckPolicy decides per message whether and when to ack. It is safe for concurrent use.
+type AckPolicy struct {
+	spec AckSpec
+	mu   sync.Mutex
+	n    uint64
+	r    *rand.Rand
+}
+
+// NewAckPolicy validates spec and returns an AckPolicy seeded with seed.
+func NewAckPolicy(spec AckSpec, seed int64) (*AckPolicy, error) {
+	spec.Mode = strings.ToLower(spec.Mode)
+	switch spec.Mode {
+	case "", "immediate", "never":
+	case "delayed":
+		if spec.Delay <= 0 {
+			return nil, fmt.Errorf("delayed ack requires ack_delay > 0")
+		}
+	case "every_n":
+		if spec.Every <= 0 {
+			return nil, fmt.Errorf("every_n ack requires ack_every > 0")
+		}
+	case "drop":
+		if spec.DropPercent < 0 || spec.DropPercent > 100 {
+			return nil, fmt.Errorf("drop ack requires ack_drop_percent within 0..100")
+		}
+	default:
+		return nil, fmt.Errorf("unknown ack mode: %q", spec.Mode)
+	}
+	return &AckPolicy{spec: spec, r: rand.New(rand.NewSource(seed))}, nil
+}
+
+// WithholdsAcks reports whether the policy leaves some messages unacked on purpose.
+func (p *AckPolicy) WithholdsAcks() bool {
+	switch p.spec.Mode {
+	case "never", "every_n":
+		return true
+	case "drop":
+		return p.spec.DropPercent > 0
+	}
+	return false
+}
+
+// Next returns the decision for the next message.
+func (p *AckPolicy) Next() AckDecision {
+	switch p.spec.Mode {
+	case "delayed":
+		return AckDecision{Ack: true, Delay: p.spec.Delay}
+	case "never":
+		return AckDecision{}
+	case "every_n":
+		p.mu.Lock()
+		p.n++
+		n := p.n
+		p.mu.Unlock()
+		return AckDecision{Ack: n%uint64(p.spec.Every) == 0}
+	case "drop":
+		p.mu.Lock()
+		drop := p.r.Float64()*100 < p.spec.DropPercent
+		p.mu.Unlock()
+		return AckDecision{Ack: !drop}
+	default:
+		return AckDecision{Ack: true}
+	}
+}
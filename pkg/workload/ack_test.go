@@ -0,0 +1,52 @@
+package workload
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAckPolicy_Modes(t *testing.T) {
+	count := func(p *AckPolicy, n int) (acked int) {
+		for i := 0; i < n; i++ {
+			if p.Next().Ack {
+				acked++
+			}
+		}
+		return acked
+	}
+
+	p, _ := NewAckPolicy(AckSpec{}, 1)
+	if got := count(p, 10); got != 10 || p.WithholdsAcks() {
+		t.Fatalf("immediate acked %d of 10", got)
+	}
+	p, _ = NewAckPolicy(AckSpec{Mode: "never"}, 1)
+	if got := count(p, 10); got != 0 || !p.WithholdsAcks() {
+		t.Fatalf("never acked %d of 10", got)
+	}
+	p, _ = NewAckPolicy(AckSpec{Mode: "every_n", Every: 3}, 1)
+	if got := count(p, 9); got != 3 {
+		t.Fatalf("every_n=3 acked %d of 9", got)
+	}
+	p, _ = NewAckPolicy(AckSpec{Mode: "drop", DropPercent: 25}, 1)
+	if got := count(p, 10000); got < 7300 || got > 7700 {
+		t.Fatalf("drop 25%% acked %d of 10000", got)
+	}
+	p, _ = NewAckPolicy(AckSpec{Mode: "delayed", Delay: time.Second}, 1)
+	if d := p.Next(); !d.Ack || d.Delay != time.Second {
+		t.Fatalf("delayed decision got %+v", d)
+	}
+}
+
+func TestAckPolicy_Invalid(t *testing.T) {
+	bad := []AckSpec{
+		{Mode: "delayed"},
+		{Mode: "every_n"},
+		{Mode: "drop", DropPercent: 101},
+		{Mode: "sometimes"},
+	}
+	for _, spec := range bad {
+		if _, err := NewAckPolicy(spec, 1); err == nil {
+			t.Fatalf("expected error for %+v", spec)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package workload
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// KeySpec describes how routing keys are drawn for a producer worker.
+type KeySpec struct {
+	Distribution string  // uniform|zipf|sequential|hot
+	Keys         int     // size of the key space
+	ZipfSkew     float64 // zipf exponent, must be > 1
+	HotKey       string  // hot key name (defaults to key-0)
+	HotPercent   float64 // share of messages sent to the hot key (defaults to 100)
+}
+
+// KeyGenerator yields the routing key for the next message.
+// Implementations are not safe for concurrent use; create one per worker.
+type KeyGenerator interface {
+	Next() string
+}
+
+// NewKeyGenerator builds a KeyGenerator for spec, seeded with seed.
+func NewKeyGenerator(spec KeySpec, seed int64) (KeyGenerator, error) {
+	r := rand.New(rand.NewSource(seed))
+	switch strings.ToLower(spec.Distribution) {
+	case "uniform":
+		if spec.Keys <= 0 {
+			return nil, fmt.Errorf("uniform keys require keys > 0")
+		}
+		return &uniformKeys{r: r, n: spec.Keys}, nil
+	case "zipf":
+		if spec.Keys <= 0 {
+			return nil, fmt.Errorf("zipf keys require keys > 0")
+		}
+		if spec.ZipfSkew <= 1 {
+			return nil, fmt.Errorf("zipf keys require zipf_skew > 1")
+		}
+		return &zipfKeys{z: rand.NewZipf(r, spec.ZipfSkew, 1, uint64(spec.Keys-1))}, nil
+	case "sequential":
+		if spec.Keys <= 0 {
+			return nil, fmt.Errorf("sequential keys require keys > 0")
+		}
+		return &sequentialKeys{n: spec.Keys}, nil
+	case "hot":
+		hot := spec.HotKey
+		if hot == "" {
+			hot = keyName(0)
+		}
+		pct := spec.HotPercent
+		if pct <= 0 || pct > 100 {
+			pct = 100
+		}
+		if pct < 100 && spec.Keys <= 0 {
+			return nil, fmt.Errorf("hot keys below 100%% require keys > 0")
+		}
+		return &hotKeys{r: r, hot: hot, pct: pct, n: spec.Keys}, nil
+	default:
+		return nil, fmt.Errorf("unknown key distribution: %q", spec.Distribution)
+	}
+}
+
+func keyName(i int) string { return fmt.Sprintf("key-%d", i) }
+
+type uniformKeys struct {
+	r *rand.Rand
+	n int
+}
+
+func (u *uniformKeys) Next() string { return keyName(u.r.Intn(u.n)) }
+
+type zipfKeys struct {
+	z *rand.Zipf
+}
+
+func (z *zipfKeys) Next() string { return keyName(int(z.z.Uint64())) }
+
+type sequentialKeys struct {
+	n    int
+	next int
+}
+
+func (s *sequentialKeys) Next() string {
+	k := keyName(s.next)
+	s.next = (s.next + 1) % s.n
+	return k
+}
+
+type hotKeys struct {
+	r   *rand.Rand
+	hot string
+	pct float64
+	n   int
+}
+
+func (h *hotKeys) Next() string {
+	if h.pct >= 100 || h.r.Float64()*100 < h.pct {
+		return h.hot
+	}
+	return keyName(h.r.Intn(h.n))
+}
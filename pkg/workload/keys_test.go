@@ -0,0 +1,91 @@
+package workload
+
+import (
+	"testing"
+)
+
+func TestKeyGenerator_Sequential(t *testing.T) {
+	g, err := NewKeyGenerator(KeySpec{Distribution: "sequential", Keys: 3}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	want := []string{"key-0", "key-1", "key-2", "key-0"}
+	for i, w := range want {
+		if got := g.Next(); got != w {
+			t.Fatalf("step %d got %q want %q", i, got, w)
+		}
+	}
+}
+
+func TestKeyGenerator_UniformStaysInRange(t *testing.T) {
+	g, err := NewKeyGenerator(KeySpec{Distribution: "uniform", Keys: 4}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		counts[g.Next()]++
+	}
+	if len(counts) != 4 {
+		t.Fatalf("expected 4 distinct keys, got %d: %v", len(counts), counts)
+	}
+	for k, n := range counts {
+		if n < 800 || n > 1200 { // expected ~1000 each
+			t.Fatalf("key %s count %d outside uniform band", k, n)
+		}
+	}
+}
+
+func TestKeyGenerator_ZipfIsSkewed(t *testing.T) {
+	g, err := NewKeyGenerator(KeySpec{Distribution: "zipf", Keys: 100, ZipfSkew: 1.5}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[g.Next()]++
+	}
+	if counts["key-0"] <= counts["key-1"] || counts["key-1"] <= counts["key-10"] {
+		t.Fatalf("zipf not skewed towards low keys: key-0=%d key-1=%d key-10=%d", counts["key-0"], counts["key-1"], counts["key-10"])
+	}
+}
+
+func TestKeyGenerator_Hot(t *testing.T) {
+	g, err := NewKeyGenerator(KeySpec{Distribution: "hot", HotKey: "h"}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	for i := 0; i < 100; i++ {
+		if k := g.Next(); k != "h" {
+			t.Fatalf("got %q want hot key", k)
+		}
+	}
+
+	g, err = NewKeyGenerator(KeySpec{Distribution: "hot", Keys: 10, HotPercent: 80}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	hot := 0
+	for i := 0; i < 10000; i++ {
+		if g.Next() == "key-0" {
+			hot++
+		}
+	}
+	if hot < 7800 || hot > 8400 { // 80% plus its uniform share of the rest
+		t.Fatalf("hot key share %d outside expected band", hot)
+	}
+}
+
+func TestKeyGenerator_Invalid(t *testing.T) {
+	bad := []KeySpec{
+		{Distribution: "uniform"},
+		{Distribution: "zipf", Keys: 10, ZipfSkew: 1},
+		{Distribution: "hot", HotPercent: 50},
+		{Distribution: "bogus", Keys: 1},
+	}
+	for _, spec := range bad {
+		if _, err := NewKeyGenerator(spec, 1); err == nil {
+			t.Fatalf("expected error for %+v", spec)
+		}
+	}
+}
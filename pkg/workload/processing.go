@@ -0,0 +1,109 @@
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProcessingSpec describes the simulated work a consumer performs per message before acking.
+type ProcessingSpec struct {
+	Mode     string        // fixed|uniform|normal|exponential|cpu
+	Duration time.Duration // fixed and cpu: per message; normal and exponential: mean
+	Min, Max time.Duration // uniform bounds
+	Stddev   time.Duration // normal
+}
+
+// Processor simulates per-message processing time. It is safe for concurrent use.
+type Processor struct {
+	spec ProcessingSpec
+	mu   sync.Mutex
+	r    *rand.Rand
+}
+
+// NewProcessor validates spec and returns a Processor seeded with seed.
+func NewProcessor(spec ProcessingSpec, seed int64) (*Processor, error) {
+	spec.Mode = strings.ToLower(spec.Mode)
+	switch spec.Mode {
+	case "fixed", "cpu", "exponential":
+		if spec.Duration <= 0 {
+			return nil, fmt.Errorf("%s processing requires duration > 0", spec.Mode)
+		}
+	case "normal":
+		if spec.Duration <= 0 || spec.Stddev < 0 {
+			return nil, fmt.Errorf("normal processing requires duration > 0 and stddev >= 0")
+		}
+	case "uniform":
+		if spec.Min < 0 || spec.Max < spec.Min {
+			return nil, fmt.Errorf("uniform processing requires 0 <= min <= max")
+		}
+	default:
+		return nil, fmt.Errorf("unknown processing mode: %q", spec.Mode)
+	}
+	return &Processor{spec: spec, r: rand.New(rand.NewSource(seed))}, nil
+}
+
+// Sample draws the processing time for the next message.
+func (p *Processor) Sample() time.Duration {
+	switch p.spec.Mode {
+	case "uniform":
+		span := int64(p.spec.Max - p.spec.Min)
+		if span == 0 {
+			return p.spec.Min
+		}
+		p.mu.Lock()
+		d := p.spec.Min + time.Duration(p.r.Int63n(span+1))
+		p.mu.Unlock()
+		return d
+	case "normal":
+		p.mu.Lock()
+		d := float64(p.spec.Duration) + p.r.NormFloat64()*float64(p.spec.Stddev)
+		p.mu.Unlock()
+		return time.Duration(math.Max(d, 0))
+	case "exponential":
+		p.mu.Lock()
+		d := p.r.ExpFloat64() * float64(p.spec.Duration)
+		p.mu.Unlock()
+		return time.Duration(d)
+	default: // fixed, cpu
+		return p.spec.Duration
+	}
+}
+
+// Process performs the simulated work for one message: sleeping, or spinning the CPU in cpu mode.
+// It returns early when ctx is done.
+func (p *Processor) Process(ctx context.Context) {
+	d := p.Sample()
+	if d <= 0 {
+		return
+	}
+	if p.spec.Mode == "cpu" {
+		burn(ctx, d)
+		return
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
+// burn keeps the CPU busy for d, checking ctx periodically.
+func burn(ctx context.Context, d time.Duration) {
+	deadline := time.Now().Add(d)
+	x := 1.0
+	for i := 0; time.Now().Before(deadline); i++ {
+		for j := 0; j < 1000; j++ {
+			x = math.Sqrt(x + float64(j))
+		}
+		if i%64 == 0 && ctx.Err() != nil {
+			return
+		}
+	}
+	_ = x
+}
@@ -0,0 +1,81 @@
+package workload
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProcessor_SampleWithinBounds(t *testing.T) {
+	p, err := NewProcessor(ProcessingSpec{Mode: "uniform", Min: 2 * time.Millisecond, Max: 5 * time.Millisecond}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if d := p.Sample(); d < 2*time.Millisecond || d > 5*time.Millisecond {
+			t.Fatalf("uniform sample %v out of bounds", d)
+		}
+	}
+
+	p, err = NewProcessor(ProcessingSpec{Mode: "normal", Duration: time.Millisecond, Stddev: 10 * time.Millisecond}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		if d := p.Sample(); d < 0 {
+			t.Fatalf("normal sample must not be negative: %v", d)
+		}
+	}
+
+	p, err = NewProcessor(ProcessingSpec{Mode: "exponential", Duration: 10 * time.Millisecond}, 1)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	var total time.Duration
+	for i := 0; i < 10000; i++ {
+		total += p.Sample()
+	}
+	if mean := total / 10000; mean < 9*time.Millisecond || mean > 11*time.Millisecond {
+		t.Fatalf("exponential mean %v too far from 10ms", mean)
+	}
+}
+
+func TestProcessor_ProcessTakesTime(t *testing.T) {
+	for _, mode := range []string{"fixed", "cpu"} {
+		p, err := NewProcessor(ProcessingSpec{Mode: mode, Duration: 20 * time.Millisecond}, 1)
+		if err != nil {
+			t.Fatalf("new %s: %v", mode, err)
+		}
+		start := time.Now()
+		p.Process(context.Background())
+		if el := time.Since(start); el < 20*time.Millisecond {
+			t.Fatalf("%s processing returned after %v", mode, el)
+		}
+	}
+
+	// cancellation cuts the work short
+	for _, mode := range []string{"fixed", "cpu"} {
+		p, _ := NewProcessor(ProcessingSpec{Mode: mode, Duration: time.Hour}, 1)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		start := time.Now()
+		p.Process(ctx)
+		cancel()
+		if el := time.Since(start); el > time.Second {
+			t.Fatalf("%s processing ignored cancellation, returned after %v", mode, el)
+		}
+	}
+}
+
+func TestProcessor_Invalid(t *testing.T) {
+	bad := []ProcessingSpec{
+		{Mode: "fixed"},
+		{Mode: "uniform", Min: 5, Max: 1},
+		{Mode: "normal", Duration: 1, Stddev: -1},
+		{Mode: "sleepy", Duration: 1},
+	}
+	for _, spec := range bad {
+		if _, err := NewProcessor(spec, 1); err == nil {
+			t.Fatalf("expected error for %+v", spec)
+		}
+	}
+}